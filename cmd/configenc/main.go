@@ -0,0 +1,83 @@
+// Command configenc encrypts values for ${enc:...} placeholders in config files.
+//
+// Usage:
+//
+//	configenc -genkey                 print a new base64 key for CONFIG_ENC_KEY
+//	configenc [-key KEY] [value]      print the ${enc:...} placeholder of value
+//	configenc -d [-key KEY] [ref]     decrypt a placeholder or its reference
+//
+// The key defaults to the CONFIG_ENC_KEY environment variable and the value to standard input.
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Jsharkc/mygopkg/config"
+)
+
+func main() {
+	genKey := flag.Bool("genkey", false, "print a new random key")
+	bits := flag.Int("bits", 256, "key size of -genkey: 128, 192 or 256")
+	decrypt := flag.Bool("d", false, "decrypt instead of encrypt")
+	keyFlag := flag.String("key", "", "base64 key, defaults to $"+config.EncKeyEnv)
+	flag.Parse()
+
+	if *genKey {
+		if *bits != 128 && *bits != 192 && *bits != 256 {
+			fatalf("-bits must be 128, 192 or 256")
+		}
+		key := make([]byte, *bits/8)
+		if _, err := rand.Read(key); err != nil {
+			fatalf("%v", err)
+		}
+		fmt.Println(base64.StdEncoding.EncodeToString(key))
+		return
+	}
+
+	var key []byte
+	var err error
+	if *keyFlag != "" {
+		key, err = base64.StdEncoding.DecodeString(*keyFlag)
+	} else {
+		key, err = config.EncKey()
+	}
+	if err != nil {
+		fatalf("key: %v", err)
+	}
+
+	value := strings.Join(flag.Args(), " ")
+	if flag.NArg() == 0 {
+		b, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		value = strings.TrimRight(string(b), "\r\n")
+	}
+
+	if *decrypt {
+		ref := strings.TrimSuffix(strings.TrimPrefix(value, "${enc:"), "}")
+		plain, err := config.DecryptValue(ref, key)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		fmt.Println(plain)
+		return
+	}
+
+	placeholder, err := config.EncryptValue(value, key)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	fmt.Println(placeholder)
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "configenc: "+format+"\n", args...)
+	os.Exit(2)
+}
@@ -0,0 +1,108 @@
+// Command configgen generates documentation of a config struct: a JSON Schema,
+// a commented sample YAML or TOML file, or a Markdown table of keys.
+//
+// Usage, from within the module defining the struct:
+//
+//	configgen -pkg example.com/svc/conf -type Config -format markdown -env svc -o CONFIG.md
+//
+// The struct is loaded by running a small generated program with the go command.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+var formats = map[string]string{
+	"schema":   "config.JSONSchema(&v)",
+	"yaml":     "config.SampleYAML(&v)",
+	"toml":     "config.SampleTOML(&v)",
+	"markdown": "config.Markdown(&v, env...)",
+}
+
+var program = template.Must(template.New("main").Parse(`package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Jsharkc/mygopkg/config"
+	target {{printf "%q" .Pkg}}
+)
+
+func main() {
+	var v target.{{.Type}}
+	env := []string{ {{- range .Env}}{{printf "%q" .}}, {{end -}} }
+	_ = env
+	out, err := {{.Call}}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Print(string(out))
+}
+`))
+
+func main() {
+	pkg := flag.String("pkg", "", "import path of the package defining the struct")
+	typ := flag.String("type", "", "name of the config struct")
+	format := flag.String("format", "markdown", "schema, yaml, toml or markdown")
+	env := flag.String("env", "", "environment variable prefix, as the parts given to IniWithEnv joined by _")
+	out := flag.String("o", "", "output file, standard output when empty")
+	flag.Parse()
+
+	call, ok := formats[*format]
+	if !ok || *pkg == "" || *typ == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	var envParts []string
+	if *env != "" {
+		envParts = strings.Split(*env, "_")
+	}
+
+	// the program lives in the current module so that the package resolves like in the build
+	dir, err := os.MkdirTemp(".", ".configgen-")
+	if err != nil {
+		fatalf("%v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var src bytes.Buffer
+	err = program.Execute(&src, struct {
+		Pkg, Type, Call string
+		Env             []string
+	}{*pkg, *typ, call, envParts})
+	if err != nil {
+		fatalf("%v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), src.Bytes(), 0644); err != nil {
+		fatalf("%v", err)
+	}
+
+	cmd := exec.Command("go", "run", "./"+filepath.ToSlash(dir))
+	cmd.Stderr = os.Stderr
+	result, err := cmd.Output()
+	if err != nil {
+		fatalf("generate: %v", err)
+	}
+
+	if *out == "" {
+		_, _ = os.Stdout.Write(result)
+		return
+	}
+	if err := os.WriteFile(*out, result, 0644); err != nil {
+		fatalf("%v", err)
+	}
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "configgen: "+format+"\n", args...)
+	os.Exit(2)
+}
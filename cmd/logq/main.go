@@ -0,0 +1,149 @@
+// Command logq filters log files written by the logger formatter encoder.
+//
+// Usage:
+//
+//	logq [flags] [file ...]
+//
+// Files may be gzipped archives left by rotation, standard input is read when none is given.
+// Examples:
+//
+//	logq -level warn -since 1h app.log
+//	logq -trace 4bf92f35 -json app.log app-2026-10-18T10-00-00.000.log.gz
+//	logq -field status>=500 -field path~/orders app.log
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Jsharkc/mygopkg/logger"
+	"go.uber.org/zap/zapcore"
+)
+
+type predicates []logger.FieldPredicate
+
+func (p *predicates) String() string {
+	return fmt.Sprint(*p)
+}
+
+func (p *predicates) Set(expr string) error {
+	pred, err := logger.ParseFieldPredicate(expr)
+	if err != nil {
+		return err
+	}
+	*p = append(*p, pred)
+	return nil
+}
+
+// parseTime accepts an absolute time or a duration meaning that long before now
+func parseTime(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{logger.TimeLayout, time.DateTime, "2006-01-02 15:04", time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("bad time %q", s)
+}
+
+func main() {
+	var (
+		filter logger.LogFilter
+		fields predicates
+	)
+	since := flag.String("since", "", "keep entries at or after this time, or this long ago (e.g. 30m)")
+	until := flag.String("until", "", "keep entries before this time, or this long ago")
+	level := flag.String("level", "", "minimum level to keep (debug, info, warn, error, ...)")
+	flag.StringVar(&filter.TraceID, "trace", "", "keep entries with this trace_id")
+	flag.StringVar(&filter.UserID, "user", "", "keep entries with this user_id")
+	flag.Var(&fields, "field", "field predicate key=v, key!=v, key~sub, key>v, key>=v, key<v, key<=v (repeatable)")
+	asJSON := flag.Bool("json", false, "write matching entries as JSON lines")
+	flag.Parse()
+
+	now := time.Now()
+	var err error
+	if filter.Since, err = parseTime(*since, now); err != nil {
+		fatalf("-since: %v", err)
+	}
+	if filter.Until, err = parseTime(*until, now); err != nil {
+		fatalf("-until: %v", err)
+	}
+	if *level != "" {
+		var l zapcore.Level
+		if err := l.UnmarshalText([]byte(strings.ToLower(*level))); err != nil {
+			fatalf("-level: %v", err)
+		}
+		filter.Level = l
+	}
+	filter.Fields = fields
+
+	out := &writer{enc: json.NewEncoder(os.Stdout), json: *asJSON}
+	if flag.NArg() == 0 {
+		r, err := logger.NewLogReader(os.Stdin)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		if err := out.copy(r, &filter); err != nil {
+			fatalf("stdin: %v", err)
+		}
+		return
+	}
+	for _, path := range flag.Args() {
+		r, err := logger.OpenLogFile(path)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		err = out.copy(r, &filter)
+		_ = r.Close()
+		if err != nil {
+			fatalf("%s: %v", path, err)
+		}
+	}
+}
+
+type writer struct {
+	enc  *json.Encoder
+	json bool
+}
+
+func (w *writer) copy(r *logger.LogReader, filter *logger.LogFilter) error {
+	for {
+		e, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !filter.Match(e) {
+			continue
+		}
+		if w.json {
+			err = w.enc.Encode(e)
+		} else {
+			_, err = fmt.Fprintln(os.Stdout, e.Raw)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "logq: "+format+"\n", args...)
+	os.Exit(2)
+}
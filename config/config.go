@@ -24,11 +24,11 @@ func Init(configName, fileType string, fpath []string, rawVal any) (*viper.Viper
 		}
 	}
 
-	if err := viperInst.Unmarshal(rawVal); err != nil {
+	if err := viperInst.Unmarshal(rawVal, decodeHook(nil)); err != nil {
 		return nil, err
 	}
 
-	log.Println("viper.AllSettings(): ", viperInst.AllSettings())
+	log.Println("config: ", dump(rawVal, placeholderKeys(viperInst)))
 	return viperInst, nil
 }
 
@@ -44,6 +44,7 @@ func IniWithEnv(configName, fileType string, fpath []string, rawVal any, parts .
 
 	// Set default values for all struct fields so AutomaticEnv binds them
 	setDefaultsForEnv(viperInst, rawVal)
+	bindMapEnv(viperInst, rawVal, prefix)
 
 	viperInst.AutomaticEnv()
 
@@ -62,50 +63,24 @@ func IniWithEnv(configName, fileType string, fpath []string, rawVal any, parts .
 		}
 	}
 
-	if err := viperInst.Unmarshal(rawVal); err != nil {
+	if err := viperInst.Unmarshal(rawVal, decodeHook(nil)); err != nil {
 		return nil, err
 	}
 
-	log.Println("viper.AllSettings(): ", viperInst.AllSettings())
+	log.Println("config: ", dump(rawVal, placeholderKeys(viperInst)))
 	return viperInst, nil
 }
 
-// setDefaultsForEnv sets default values for all struct fields so AutomaticEnv will bind them
+// setDefaultsForEnv sets default values for all struct fields so AutomaticEnv will bind them,
+// map fields are bound from KEY__SUBKEY variables instead, see EnvVars
 func setDefaultsForEnv(v *viper.Viper, iface interface{}) {
-	ift := reflect.TypeOf(iface)
-
-	if ift.Kind() == reflect.Ptr {
-		ift = ift.Elem()
-	}
-
-	setDefaultsRecursive(v, ift, "")
-}
-
-func setDefaultsRecursive(v *viper.Viper, t reflect.Type, prefix string) {
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-		tagValue := field.Tag.Get("mapstructure")
-		if tagValue == "" {
-			tagValue = strings.ToLower(field.Name)
+	for _, k := range envKeys(iface) {
+		if k.isMap {
+			continue
 		}
-		// Handle tag options like "name,omitempty"
-		if idx := strings.Index(tagValue, ","); idx != -1 {
-			tagValue = tagValue[:idx]
-		}
-
-		fullKey := tagValue
-		if prefix != "" {
-			fullKey = prefix + "." + tagValue
-		}
-
-		switch field.Type.Kind() {
-		case reflect.Struct:
-			setDefaultsRecursive(v, field.Type, fullKey)
-		default:
-			// Set default value so AutomaticEnv will bind this key to environment variable
-			if !v.IsSet(fullKey) {
-				v.SetDefault(fullKey, reflect.Zero(field.Type).Interface())
-			}
+		// Set default value so AutomaticEnv will bind this key to environment variable
+		if !v.IsSet(k.key) {
+			v.SetDefault(k.key, reflect.Zero(k.typ).Interface())
 		}
 	}
 }
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/viper"
+)
+
+// EnvMapSep separates a map field from its keys in environment variable names,
+// e.g. APP_LABELS__TEAM sets labels.team
+const EnvMapSep = "__"
+
+// decodeHook extends the viper decoding of durations and comma separated lists
+// with time.Time values written in RFC 3339, as they come from the environment,
+// after resolving the ${scheme:ref} placeholders of the values
+func decodeHook(local map[string]Resolver) viper.DecoderConfigOption {
+	return viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		resolveHook(local),
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToTimeHookFunc(time.RFC3339),
+		mapstructure.StringToWeakSliceHookFunc(","),
+	))
+}
+
+// envKey is a key of the configuration that can be set from the environment
+type envKey struct {
+	key   string
+	typ   reflect.Type
+	isMap bool
+}
+
+// envKeys lists the keys of the struct rawVal points to: nested and embedded structs,
+// pointers to structs and squashed fields are walked, time.Time is a single value and
+// slices of structs cannot be expressed in a variable so they are left out
+func envKeys(rawVal any) []envKey {
+	t := reflect.TypeOf(rawVal)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	var keys []envKey
+	envKeysRecursive(t, "", &keys)
+	return keys
+}
+
+func envKeysRecursive(t reflect.Type, prefix string, keys *[]envKey) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key, squash, ok := fieldKey(field)
+		if !ok {
+			continue
+		}
+		fullKey := joinKey(prefix, key)
+		if squash {
+			fullKey = prefix
+		}
+
+		ft := field.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		switch {
+		case ft.Kind() == reflect.Struct && !isLeafType(ft):
+			envKeysRecursive(ft, fullKey, keys)
+		case ft.Kind() == reflect.Map:
+			*keys = append(*keys, envKey{key: fullKey, typ: ft, isMap: true})
+		case ft.Kind() == reflect.Slice && isStructType(ft.Elem()):
+		default:
+			*keys = append(*keys, envKey{key: fullKey, typ: field.Type})
+		}
+	}
+}
+
+func isStructType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !isLeafType(t)
+}
+
+// bindMapEnv binds the variables named <MAP>__<KEY>[__<SUBKEY>...] to the keys
+// of the map fields of rawVal, which AutomaticEnv cannot discover by itself;
+// it returns the variable bound to each key
+func bindMapEnv(v *viper.Viper, rawVal any, prefix string) map[string]string {
+	var maps []string
+	for _, k := range envKeys(rawVal) {
+		if k.isMap {
+			maps = append(maps, k.key)
+		}
+	}
+	if len(maps) == 0 {
+		return nil
+	}
+
+	bound := make(map[string]string)
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		for _, key := range maps {
+			sub, ok := strings.CutPrefix(name, envName(prefix, key)+EnvMapSep)
+			if !ok || sub == "" {
+				continue
+			}
+			subKey := strings.ToLower(strings.ReplaceAll(sub, EnvMapSep, "."))
+			_ = v.BindEnv(key+"."+subKey, name)
+			bound[key+"."+subKey] = name
+		}
+	}
+	return bound
+}
+
+// EnvBinding describes an environment variable accepted by IniWithEnv or Load
+type EnvBinding struct {
+	// Name of the variable, map fields end with __<KEY>
+	Name string
+	// Key is the configuration key the variable sets
+	Key string
+	// Type is the Go type of the field, lists are comma separated
+	Type string
+}
+
+// EnvVars lists the environment variables accepted for the struct rawVal points to,
+// parts form the prefix like in IniWithEnv
+func EnvVars(rawVal any, parts ...string) []EnvBinding {
+	prefix := strings.Join(parts, "_")
+	var vars []EnvBinding
+	for _, k := range envKeys(rawVal) {
+		name := envName(prefix, k.key)
+		if k.isMap {
+			name += EnvMapSep + "<KEY>"
+		}
+		vars = append(vars, EnvBinding{Name: name, Key: k.key, Type: k.typ.String()})
+	}
+	sort.Slice(vars, func(i, j int) bool {
+		return vars[i].Name < vars[j].Name
+	})
+	return vars
+}
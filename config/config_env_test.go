@@ -0,0 +1,92 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type EnvBase struct {
+	Region string `mapstructure:"region"`
+}
+
+type EnvConfig struct {
+	EnvBase  `mapstructure:",squash"`
+	DB       *DBConfig          `mapstructure:"db"`
+	Hosts    []string           `mapstructure:"hosts"`
+	Ports    []int              `mapstructure:"ports"`
+	Timeout  time.Duration      `mapstructure:"timeout"`
+	Started  time.Time          `mapstructure:"started"`
+	Labels   map[string]string  `mapstructure:"labels"`
+	Backends map[string]Backend `mapstructure:"backends"`
+	Routes   []Backend          `mapstructure:"routes"`
+	Ignored  string             `mapstructure:"-"`
+}
+
+type Backend struct {
+	URL string `mapstructure:"url"`
+}
+
+func TestIniWithEnvBinding(t *testing.T) {
+	t.Setenv("ENVT_REGION", "eu")
+	t.Setenv("ENVT_DB_HOST", "db.local")
+	t.Setenv("ENVT_DB_PASSWORD", "pw")
+	t.Setenv("ENVT_HOSTS", "a,b,c")
+	t.Setenv("ENVT_PORTS", "80,443")
+	t.Setenv("ENVT_TIMEOUT", "1m30s")
+	t.Setenv("ENVT_STARTED", "2026-10-18T09:00:00Z")
+	t.Setenv("ENVT_LABELS__TEAM", "core")
+	t.Setenv("ENVT_LABELS__COST_CENTER", "42")
+	t.Setenv("ENVT_BACKENDS__MAIN__URL", "http://main")
+
+	dir := t.TempDir()
+	writeConfig(t, dir, "env.yaml", "labels:\n  tier: gold\n")
+
+	var cfg EnvConfig
+	_, err := IniWithEnv("env", "yaml", []string{dir}, &cfg, "envt")
+	assert.NoError(t, err)
+	assert.Equal(t, "eu", cfg.Region)
+	assert.Equal(t, "db.local", cfg.DB.Host)
+	assert.Equal(t, "pw", cfg.DB.Password)
+	assert.Equal(t, []string{"a", "b", "c"}, cfg.Hosts)
+	assert.Equal(t, []int{80, 443}, cfg.Ports)
+	assert.Equal(t, 90*time.Second, cfg.Timeout)
+	assert.Equal(t, "2026-10-18T09:00:00Z", cfg.Started.Format(time.RFC3339))
+	assert.Equal(t, map[string]string{"team": "core", "cost_center": "42", "tier": "gold"}, cfg.Labels)
+	assert.Equal(t, map[string]Backend{"main": {URL: "http://main"}}, cfg.Backends)
+}
+
+func TestIniWithEnvNonStruct(t *testing.T) {
+	var n int
+	assert.NotPanics(t, func() {
+		_, err := IniWithEnv("none", "yaml", []string{t.TempDir()}, &n)
+		assert.Error(t, err)
+	})
+	assert.NotPanics(t, func() {
+		_, err := IniWithEnv("none", "yaml", []string{t.TempDir()}, nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestEnvVars(t *testing.T) {
+	vars := EnvVars(&EnvConfig{}, "app")
+	names := make([]string, len(vars))
+	for i, v := range vars {
+		names[i] = v.Name
+	}
+	assert.Equal(t, []string{
+		"APP_BACKENDS__<KEY>",
+		"APP_DB_HOST",
+		"APP_DB_PASSWORD",
+		"APP_DB_PORT",
+		"APP_HOSTS",
+		"APP_LABELS__<KEY>",
+		"APP_PORTS",
+		"APP_REGION",
+		"APP_STARTED",
+		"APP_TIMEOUT",
+	}, names)
+	assert.Equal(t, EnvBinding{Name: "APP_TIMEOUT", Key: "timeout", Type: "time.Duration"}, vars[9])
+	assert.Nil(t, EnvVars(42))
+}
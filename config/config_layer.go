@@ -0,0 +1,143 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// EnvVar is the environment variable selecting the overlay file when neither
+// Options.Env nor App.Env is set
+const EnvVar = "APP_ENV"
+
+// Layers that may supply the value of a key, see Source
+const (
+	LayerDefault = "default"
+	LayerFile    = "file"
+	LayerEnv     = "env"
+	LayerFlag    = "flag"
+)
+
+// Source tells where the final value of a key comes from
+type Source struct {
+	Layer string
+	// Name is the file path, environment variable or flag name supplying the value
+	Name string
+}
+
+func (s Source) String() string {
+	if s.Name == "" {
+		return s.Layer
+	}
+	return s.Layer + ":" + s.Name
+}
+
+// Sources maps every key of the configuration to the source of its value
+type Sources map[string]Source
+
+func (o Options) envPrefix() string {
+	return strings.Join(o.EnvPrefix, "_")
+}
+
+// env returns the selected environment and records it in App.Env
+func (o Options) env() string {
+	env := o.Env
+	if env == "" && o.App != nil {
+		env = o.App.Env
+	}
+	if env == "" {
+		env = os.Getenv(EnvVar)
+	}
+	if o.App != nil && o.App.Env == "" {
+		o.App.Env = env
+	}
+	return env
+}
+
+// configFile is a config file read into the configuration
+type configFile struct {
+	path string
+	keys map[string]bool
+}
+
+// readLayers merges the base, environment and local files into v in that order
+func readLayers(v *viper.Viper, opts Options) ([]configFile, error) {
+	names := []string{opts.Name}
+	if env := opts.env(); env != "" && env != "local" {
+		names = append(names, opts.Name+"."+env)
+	}
+	names = append(names, opts.Name+".local")
+
+	var files []configFile
+	for _, name := range names {
+		layer := viper.New()
+		layer.SetConfigName(name)
+		layer.SetConfigType(opts.Type)
+		for _, path := range opts.Paths {
+			layer.AddConfigPath(path)
+		}
+		if err := layer.ReadInConfig(); err != nil {
+			var configFileNotFoundError viper.ConfigFileNotFoundError
+			if errors.As(err, &configFileNotFoundError) {
+				continue
+			}
+			return nil, err
+		}
+		if err := v.MergeConfigMap(layer.AllSettings()); err != nil {
+			return nil, fmt.Errorf("config: merge %s: %w", layer.ConfigFileUsed(), err)
+		}
+
+		file := configFile{path: layer.ConfigFileUsed(), keys: make(map[string]bool)}
+		for _, key := range layer.AllKeys() {
+			file.keys[key] = true
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// envName returns the environment variable viper reads key from
+func envName(prefix, key string) string {
+	name := strings.ReplaceAll(key, ".", "_")
+	if prefix != "" {
+		name = prefix + "_" + name
+	}
+	return strings.ToUpper(name)
+}
+
+// sources resolves the layer of every key, mapEnv holds the variables bindMapEnv
+// bound to map keys
+func sources(v *viper.Viper, opts Options, files []configFile, mapEnv map[string]string) Sources {
+	out := make(Sources)
+	prefix := opts.envPrefix()
+	for _, key := range v.AllKeys() {
+		if opts.Flags != nil {
+			if f := opts.Flags.Lookup(key); f != nil && f.Changed {
+				out[key] = Source{Layer: LayerFlag, Name: f.Name}
+				continue
+			}
+		}
+		if opts.BindEnv {
+			name, ok := mapEnv[key]
+			if !ok {
+				name = envName(prefix, key)
+			}
+			// viper ignores empty variables
+			if os.Getenv(name) != "" {
+				out[key] = Source{Layer: LayerEnv, Name: name}
+				continue
+			}
+		}
+		source := Source{Layer: LayerDefault}
+		for _, file := range files {
+			if file.keys[key] {
+				source = Source{Layer: LayerFile, Name: file.path}
+			}
+		}
+		out[key] = source
+	}
+	return out
+}
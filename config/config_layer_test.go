@@ -0,0 +1,81 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Jsharkc/mygopkg/app"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadLayers(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "server.yaml", `
+mode: test
+token: abcdefgh
+db:
+  host: base
+  port: 1000
+`)
+	writeConfig(t, dir, "server.prod.yaml", `
+db:
+  host: prod
+  port: 2000
+`)
+	writeConfig(t, dir, "server.dev.yaml", "db:\n  host: dev\n")
+	writeConfig(t, dir, "server.local.yaml", "db:\n  port: 3000\n")
+
+	t.Setenv("SVC_TOKEN", "from-env")
+	t.Setenv(EnvVar, "dev")
+	flags := pflag.NewFlagSet("server", pflag.ContinueOnError)
+	flags.String("mode", "release", "")
+	flags.Int("db.port", 0, "")
+	assert.NoError(t, flags.Parse([]string{"--mode=debug"}))
+
+	application := &app.App{}
+	cfg, sources, err := LoadWithSources[ServerConfig](Options{
+		Name: "server", Type: "yaml", Paths: []string{dir},
+		BindEnv: true, EnvPrefix: []string{"svc"},
+		Env: "prod", App: application, Flags: flags,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "prod", application.Env)
+	assert.Equal(t, "debug", cfg.Mode)
+	assert.Equal(t, "prod", cfg.DB.Host)
+	assert.Equal(t, 3000, cfg.DB.Port)
+	assert.Equal(t, "from-env", cfg.Token)
+	assert.Equal(t, "30s", cfg.Timeout.String())
+
+	assert.Equal(t, Source{Layer: LayerFlag, Name: "mode"}, sources["mode"])
+	assert.Equal(t, Source{Layer: LayerFile, Name: filepath.Join(dir, "server.prod.yaml")}, sources["db.host"])
+	assert.Equal(t, Source{Layer: LayerFile, Name: filepath.Join(dir, "server.local.yaml")}, sources["db.port"])
+	assert.Equal(t, "env:SVC_TOKEN", sources["token"].String())
+	assert.Equal(t, LayerDefault, sources["timeout"].String())
+
+	// App.Env and then APP_ENV select the overlay when Options.Env is empty
+	cfg, _, err = Load[ServerConfig](Options{Name: "server", Type: "yaml", Paths: []string{dir}, App: &app.App{Env: "prod"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "prod", cfg.DB.Host)
+	cfg, _, err = Load[ServerConfig](Options{Name: "server", Type: "yaml", Paths: []string{dir}})
+	assert.NoError(t, err)
+	assert.Equal(t, "dev", cfg.DB.Host)
+	assert.Equal(t, 3000, cfg.DB.Port)
+}
+
+func TestLoadSourcesMapEnv(t *testing.T) {
+	t.Setenv("SRCT_LABELS__COST_CENTER", "42")
+	t.Setenv("SRCT_BACKENDS__MAIN__URL", "http://main")
+	t.Setenv("SRCT_REGION", "eu")
+	t.Setenv("SRCT_DB_HOST", "db")
+
+	cfg, sources, err := LoadWithSources[EnvConfig](Options{
+		Name: "none", Type: "yaml", Paths: []string{t.TempDir()},
+		BindEnv: true, EnvPrefix: []string{"srct"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "42", cfg.Labels["cost_center"])
+	assert.Equal(t, Source{Layer: LayerEnv, Name: "SRCT_LABELS__COST_CENTER"}, sources["labels.cost_center"])
+	assert.Equal(t, Source{Layer: LayerEnv, Name: "SRCT_BACKENDS__MAIN__URL"}, sources["backends.main.url"])
+	assert.Equal(t, Source{Layer: LayerEnv, Name: "SRCT_REGION"}, sources["region"])
+}
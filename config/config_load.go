@@ -0,0 +1,177 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	"github.com/Jsharkc/mygopkg/app"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// Options describes where Load reads the configuration from
+type Options struct {
+	// Name is the config file name without extension
+	Name string
+	// Type is the config file type, e.g. yaml, toml or json
+	Type  string
+	Paths []string
+	// BindEnv lets environment variables override the files, see IniWithEnv
+	BindEnv bool
+	// EnvPrefix parts are joined with "_" to prefix the environment variables
+	EnvPrefix []string
+
+	// Env selects the <Name>.<Env> overlay file, when empty it is taken
+	// from App.Env and then from the APP_ENV environment variable
+	Env string
+	// App receives the selected environment in App.Env if not set yet
+	App *app.App
+	// Flags override every other layer for the flags set on the command line,
+	// a flag applies to the key of the same name, e.g. --db.port
+	Flags *pflag.FlagSet
+
+	// Resolvers resolve ${scheme:ref} placeholders in values before the registered ones
+	Resolvers map[string]Resolver
+}
+
+// Load reads the configuration into a new T from these layers, each overriding the previous:
+//
+//	default tags of the fields of T
+//	<Name>.<ext>          the base file
+//	<Name>.<env>.<ext>    the overlay of the selected environment
+//	<Name>.local.<ext>    local overrides, usually not committed
+//	environment variables when BindEnv is set
+//	command line flags
+//
+// Missing files are skipped. Placeholders like ${env:DB_PASS}, ${file:/run/secrets/db}
+// or ${enc:...} are replaced by the value they refer to, see RegisterResolver. The result is checked against the validate tags
+// and logged with the fields tagged secret:"true" and those set from placeholders masked
+func Load[T any](opts Options) (*T, *viper.Viper, error) {
+	cfg, viperInst, _, err := load[T](opts)
+	return cfg, viperInst, err
+}
+
+// LoadWithSources is Load also reporting the layer that supplied the final value of each key
+func LoadWithSources[T any](opts Options) (*T, Sources, error) {
+	cfg, _, sources, err := load[T](opts)
+	return cfg, sources, err
+}
+
+func load[T any](opts Options) (*T, *viper.Viper, Sources, error) {
+	cfg := new(T)
+	if reflect.TypeOf(cfg).Elem().Kind() != reflect.Struct {
+		return nil, nil, nil, fmt.Errorf("config: Load needs a struct type, got %T", *cfg)
+	}
+
+	viperInst := viper.New()
+	if err := setTagDefaults(viperInst, cfg); err != nil {
+		return nil, nil, nil, err
+	}
+	var mapEnv map[string]string
+	if opts.BindEnv {
+		viperInst.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+		if prefix := opts.envPrefix(); prefix != "" {
+			viperInst.SetEnvPrefix(prefix)
+		}
+		setDefaultsForEnv(viperInst, cfg)
+		mapEnv = bindMapEnv(viperInst, cfg, opts.envPrefix())
+		viperInst.AutomaticEnv()
+	}
+
+	files, err := readLayers(viperInst, opts)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if opts.Flags != nil {
+		if err := viperInst.BindPFlags(opts.Flags); err != nil {
+			return nil, nil, nil, fmt.Errorf("config: bind flags: %w", err)
+		}
+	}
+
+	if err := viperInst.Unmarshal(cfg, decodeHook(opts.Resolvers)); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := Validate(cfg); err != nil {
+		return nil, nil, nil, err
+	}
+
+	log.Println("config: ", dump(cfg, placeholderKeys(viperInst)))
+	return cfg, viperInst, sources(viperInst, opts, files, mapEnv), nil
+}
+
+// fieldKey returns the key of a struct field in the configuration, following
+// the mapstructure tag; squash reports the fields of an embedded struct are
+// promoted to the parent and ok is false for fields that are not decoded
+func fieldKey(field reflect.StructField) (key string, squash, ok bool) {
+	if !field.IsExported() {
+		return "", false, false
+	}
+	tag := field.Tag.Get("mapstructure")
+	if tag == "-" {
+		return "", false, false
+	}
+	key, opts, _ := strings.Cut(tag, ",")
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == "squash" {
+			squash = true
+		}
+	}
+	if key == "" {
+		key = strings.ToLower(field.Name)
+	}
+	return key, squash, true
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// setTagDefaults registers the default tags of the fields of rawVal as viper defaults,
+// values are kept as text and converted when unmarshalling
+func setTagDefaults(v *viper.Viper, rawVal any) error {
+	t := reflect.TypeOf(rawVal)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("config: defaults need a struct, got %T", rawVal)
+	}
+	setTagDefaultsRecursive(v, t, "")
+	return nil
+}
+
+func setTagDefaultsRecursive(v *viper.Viper, t reflect.Type, prefix string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key, squash, ok := fieldKey(field)
+		if !ok {
+			continue
+		}
+		fullKey := joinKey(prefix, key)
+		if squash {
+			fullKey = prefix
+		}
+
+		if def, ok := field.Tag.Lookup("default"); ok {
+			v.SetDefault(fullKey, def)
+			continue
+		}
+		ft := field.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct && !isLeafType(ft) {
+			setTagDefaultsRecursive(v, ft, fullKey)
+		}
+	}
+}
+
+// isLeafType reports struct types decoded as a single value
+func isLeafType(t reflect.Type) bool {
+	return t == timeType
+}
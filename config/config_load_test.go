@@ -0,0 +1,143 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type DBConfig struct {
+	Host     string `mapstructure:"host" validate:"required"`
+	Port     int    `mapstructure:"port" default:"5432" validate:"min=1,max=65535"`
+	Password string `mapstructure:"password" secret:"true"`
+}
+
+type ServerConfig struct {
+	Mode    string        `mapstructure:"mode" default:"release" validate:"oneof=debug release test"`
+	Timeout time.Duration `mapstructure:"timeout" default:"30s" validate:"min=1s"`
+	Tags    []string      `mapstructure:"tags" default:"a,b"`
+	DB      *DBConfig     `mapstructure:"db"`
+	Token   string        `mapstructure:"token" secret:"true" validate:"len=8"`
+	Common  `mapstructure:",squash"`
+}
+
+type Common struct {
+	Region string `mapstructure:"region" default:"cn"`
+}
+
+func writeConfig(t *testing.T, dir, name, content string) {
+	t.Helper()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "server.yaml", `
+timeout: 5s
+token: abcdefgh
+db:
+  host: localhost
+  password: s3cret
+`)
+
+	cfg, v, err := Load[ServerConfig](Options{Name: "server", Type: "yaml", Paths: []string{dir}})
+	assert.NoError(t, err)
+	assert.NotNil(t, v)
+	assert.Equal(t, "release", cfg.Mode)
+	assert.Equal(t, 5*time.Second, cfg.Timeout)
+	assert.Equal(t, []string{"a", "b"}, cfg.Tags)
+	assert.Equal(t, "localhost", cfg.DB.Host)
+	assert.Equal(t, 5432, cfg.DB.Port)
+	assert.Equal(t, "cn", cfg.Region)
+
+	t.Setenv("SVC_MODE", "debug")
+	t.Setenv("SVC_DB_PORT", "6543")
+	cfg, _, err = Load[ServerConfig](Options{Name: "server", Type: "yaml", Paths: []string{dir}, BindEnv: true, EnvPrefix: []string{"svc"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "debug", cfg.Mode)
+	assert.Equal(t, 6543, cfg.DB.Port)
+
+	_, _, err = Load[int](Options{Name: "server", Type: "yaml", Paths: []string{dir}})
+	assert.Error(t, err)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "server.yaml", `
+mode: prod
+timeout: 10ms
+token: short
+db:
+  port: 70000
+`)
+
+	_, _, err := Load[ServerConfig](Options{Name: "server", Type: "yaml", Paths: []string{dir}})
+	var errs ValidationErrors
+	assert.True(t, errors.As(err, &errs))
+
+	paths := map[string]string{}
+	for _, e := range errs {
+		paths[e.Path] = e.Rule
+	}
+	assert.Equal(t, map[string]string{
+		"mode":    "oneof",
+		"timeout": "min",
+		"token":   "len",
+		"db.host": "required",
+		"db.port": "max",
+	}, paths)
+	assert.Contains(t, err.Error(), "db.port: must be at most 65535, got 70000")
+	assert.Contains(t, err.Error(), "timeout: must be at least 1s, got 10ms")
+	assert.NotContains(t, err.Error(), "short")
+}
+
+func TestValidate(t *testing.T) {
+	type item struct {
+		Name string `validate:"required"`
+	}
+	type cfg struct {
+		Items   []item          `validate:"min=1"`
+		ByName  map[string]item `mapstructure:"by_name"`
+		Ratio   *float64        `validate:"max=1"`
+		Unknown string          `validate:"email"`
+	}
+
+	two := 2.0
+	err := Validate(&cfg{
+		Items:  []item{{Name: "a"}, {}},
+		ByName: map[string]item{"x": {}},
+		Ratio:  &two,
+	})
+	var errs ValidationErrors
+	assert.True(t, errors.As(err, &errs))
+	assert.Len(t, errs, 4)
+	assert.Contains(t, err.Error(), "items[1].name: is required")
+	assert.Contains(t, err.Error(), "by_name.x.name: is required")
+	assert.Contains(t, err.Error(), "ratio: must be at most 1, got 2")
+	assert.Contains(t, err.Error(), `unknown: unknown rule "email"`)
+
+	assert.NoError(t, Validate(&item{Name: "a"}))
+	assert.Error(t, Validate(42))
+}
+
+func TestRedact(t *testing.T) {
+	cfg := ServerConfig{
+		Mode:    "debug",
+		Timeout: time.Second,
+		DB:      &DBConfig{Host: "db", Port: 1, Password: "s3cret"},
+		Common:  Common{Region: "eu"},
+	}
+	assert.Equal(t, map[string]any{
+		"mode":    "debug",
+		"timeout": "1s",
+		"tags":    nil,
+		"db":      map[string]any{"host": "db", "port": 1, "password": RedactedValue},
+		"token":   "",
+		"region":  "eu",
+	}, Redact(&cfg))
+	assert.NotContains(t, Dump(cfg), "s3cret")
+}
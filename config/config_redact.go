@@ -0,0 +1,143 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// RedactedValue replaces the values of fields tagged secret:"true"
+const RedactedValue = "******"
+
+// Redact converts the configuration in v to a map keyed like the config file,
+// the non-empty values of fields tagged secret:"true" are replaced by RedactedValue
+func Redact(v any) map[string]any {
+	return toMap(v, true)
+}
+
+// toMap converts the struct in v to a map keyed like the config file
+func toMap(v any, redact bool) map[string]any {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	out := make(map[string]any)
+	redactStruct(rv, out, redact)
+	return out
+}
+
+// Dump formats the redacted configuration in v as JSON for logging
+func Dump(v any) string {
+	return dump(v, nil)
+}
+
+// dump is Dump also redacting the values at keys, e.g. db.password
+func dump(v any, keys []string) string {
+	m := Redact(v)
+	for _, key := range keys {
+		redactKey(m, strings.Split(key, "."))
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Sprintf("<%v>", err)
+	}
+	return string(b)
+}
+
+// redactKey replaces the non-empty value at path in m by RedactedValue,
+// keys are matched case-insensitively as viper lowercases them
+func redactKey(m map[string]any, path []string) {
+	for k, v := range m {
+		if !strings.EqualFold(k, path[0]) {
+			continue
+		}
+		if len(path) > 1 {
+			if sub, ok := v.(map[string]any); ok {
+				redactKey(sub, path[1:])
+			}
+			continue
+		}
+		if v != nil && v != "" {
+			m[k] = RedactedValue
+		}
+	}
+}
+
+func redactStruct(rv reflect.Value, out map[string]any, redact bool) {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key, squash, ok := fieldKey(field)
+		if !ok {
+			continue
+		}
+
+		fv := rv.Field(i)
+		if redact && field.Tag.Get("secret") == "true" {
+			if isEmpty(fv) {
+				out[key] = ""
+			} else {
+				out[key] = RedactedValue
+			}
+			continue
+		}
+		if squash {
+			if sv := reflect.Indirect(fv); sv.Kind() == reflect.Struct {
+				redactStruct(sv, out, redact)
+				continue
+			}
+		}
+		out[key] = redactValue(fv, redact)
+	}
+}
+
+func redactValue(rv reflect.Value, redact bool) any {
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+		return redactValue(rv.Elem(), redact)
+	case reflect.Struct:
+		if rv.Type() == timeType {
+			return rv.Interface()
+		}
+		out := make(map[string]any)
+		redactStruct(rv, out, redact)
+		return out
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil
+		}
+		items := make([]any, rv.Len())
+		for i := range items {
+			items[i] = redactValue(rv.Index(i), redact)
+		}
+		return items
+	case reflect.Map:
+		if rv.IsNil() {
+			return nil
+		}
+		out := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			out[fmt.Sprint(iter.Key().Interface())] = redactValue(iter.Value(), redact)
+		}
+		return out
+	}
+	if rv.Type() == durationType {
+		return time.Duration(rv.Int()).String()
+	}
+	if !rv.CanInterface() {
+		return nil
+	}
+	return rv.Interface()
+}
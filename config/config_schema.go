@@ -0,0 +1,433 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fieldDoc describes a key of the configuration for the generated documents
+type fieldDoc struct {
+	key    string
+	name   string
+	typ    reflect.Type
+	desc   string
+	def    string
+	hasDef bool
+	secret bool
+	rules  map[string]string
+	// fields of a struct, or of the elements of a slice or map of structs
+	fields []*fieldDoc
+}
+
+// describe builds the documentation of the struct rawVal points to
+func describe(rawVal any) ([]*fieldDoc, error) {
+	t := reflect.TypeOf(rawVal)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config: cannot describe %T, need a struct", rawVal)
+	}
+	return describeStruct(t, ""), nil
+}
+
+func describeStruct(t reflect.Type, prefix string) []*fieldDoc {
+	var docs []*fieldDoc
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key, squash, ok := fieldKey(field)
+		if !ok {
+			continue
+		}
+		ft := derefType(field.Type)
+		if ft.Kind() == reflect.Func || ft.Kind() == reflect.Chan {
+			continue
+		}
+		if squash && ft.Kind() == reflect.Struct {
+			docs = append(docs, describeStruct(ft, prefix)...)
+			continue
+		}
+
+		doc := &fieldDoc{
+			key:    joinKey(prefix, key),
+			name:   key,
+			typ:    ft,
+			desc:   field.Tag.Get("desc"),
+			secret: field.Tag.Get("secret") == "true",
+			rules:  make(map[string]string),
+		}
+		doc.def, doc.hasDef = field.Tag.Lookup("default")
+		for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+			if name, param, _ := strings.Cut(strings.TrimSpace(rule), "="); name != "" {
+				doc.rules[name] = param
+			}
+		}
+
+		switch {
+		case isStructType(ft):
+			doc.fields = describeStruct(ft, doc.key)
+		case (ft.Kind() == reflect.Slice || ft.Kind() == reflect.Map) && isStructType(ft.Elem()):
+			elemPrefix := doc.key + "[]"
+			if ft.Kind() == reflect.Map {
+				elemPrefix = doc.key + ".<key>"
+			}
+			doc.fields = describeStruct(derefType(ft.Elem()), elemPrefix)
+		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// typeName returns the type of a key as shown in the documents
+func typeName(t reflect.Type) string {
+	t = derefType(t)
+	switch {
+	case t == durationType:
+		return "duration"
+	case t == timeType:
+		return "time"
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return "list of " + typeName(t.Elem())
+	case reflect.Map:
+		return "map of " + typeName(t.Elem())
+	case reflect.Struct:
+		return "object"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	}
+	return t.Kind().String()
+}
+
+// typedValue converts the text of a default tag or a oneof rule to the JSON value of type t
+func typedValue(t reflect.Type, s string) any {
+	t = derefType(t)
+	switch typeName(t) {
+	case "integer":
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	}
+	if t.Kind() == reflect.Slice && t != durationType {
+		if s == "" {
+			return []any{}
+		}
+		parts := strings.Split(s, ",")
+		items := make([]any, len(parts))
+		for i, p := range parts {
+			items[i] = typedValue(t.Elem(), strings.TrimSpace(p))
+		}
+		return items
+	}
+	return s
+}
+
+// JSONSchema generates a JSON Schema of the struct rawVal points to with the types,
+// defaults, descriptions from the desc tags and the constraints of the validate tags
+func JSONSchema(rawVal any) ([]byte, error) {
+	docs, err := describe(rawVal)
+	if err != nil {
+		return nil, err
+	}
+	schema := objectSchema(docs)
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+func objectSchema(docs []*fieldDoc) map[string]any {
+	props := make(map[string]any, len(docs))
+	var required []string
+	for _, doc := range docs {
+		props[doc.name] = fieldSchema(doc)
+		if _, ok := doc.rules["required"]; ok {
+			required = append(required, doc.name)
+		}
+	}
+	schema := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func fieldSchema(doc *fieldDoc) map[string]any {
+	schema := typeSchema(doc.typ, doc.fields)
+	if doc.desc != "" {
+		schema["description"] = doc.desc
+	}
+	if doc.hasDef {
+		schema["default"] = typedValue(doc.typ, doc.def)
+	}
+	if doc.secret {
+		schema["writeOnly"] = true
+	}
+	if oneof, ok := doc.rules["oneof"]; ok {
+		var enum []any
+		for _, v := range strings.Fields(oneof) {
+			enum = append(enum, typedValue(doc.typ, v))
+		}
+		schema["enum"] = enum
+	}
+
+	if doc.typ == durationType {
+		return schema
+	}
+	bounds := map[string][2]string{
+		"integer": {"minimum", "maximum"},
+		"number":  {"minimum", "maximum"},
+		"string":  {"minLength", "maxLength"},
+		"array":   {"minItems", "maxItems"},
+		"object":  {"minProperties", "maxProperties"},
+	}
+	names, ok := bounds[fmt.Sprint(schema["type"])]
+	if !ok {
+		return schema
+	}
+	for rule, i := range map[string]int{"min": 0, "max": 1} {
+		if param, ok := doc.rules[rule]; ok {
+			schema[names[i]] = typedValue(reflect.TypeOf(float64(0)), param)
+		}
+	}
+	if param, ok := doc.rules["len"]; ok {
+		n := typedValue(reflect.TypeOf(float64(0)), param)
+		schema[names[0]], schema[names[1]] = n, n
+	}
+	return schema
+}
+
+func typeSchema(t reflect.Type, fields []*fieldDoc) map[string]any {
+	t = derefType(t)
+	switch {
+	case t == durationType:
+		return map[string]any{"type": "string", "format": "duration"}
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return objectSchema(fields)
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem(), fields)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem(), fields)}
+	}
+	switch name := typeName(t); name {
+	case "integer", "number", "boolean":
+		return map[string]any{"type": name}
+	}
+	return map[string]any{"type": "string"}
+}
+
+// sampleValue returns the value of a key in the sample files, quoted as valid YAML and TOML
+func sampleValue(doc *fieldDoc) string {
+	var v any = zeroSample(doc.typ)
+	if doc.hasDef {
+		v = typedValue(doc.typ, doc.def)
+	}
+	if items, ok := v.([]any); ok {
+		parts := make([]string, len(items))
+		for i, item := range items {
+			b, _ := json.Marshal(item)
+			parts[i] = string(b)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func zeroSample(t reflect.Type) any {
+	switch typeName(t) {
+	case "duration":
+		return "0s"
+	case "time":
+		// the zero time in RFC 3339, which decodeHook reads back as time.Time{}
+		return time.Time{}.Format(time.RFC3339)
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return []any{}
+	case reflect.Map:
+		return map[string]any{}
+	}
+	return ""
+}
+
+// comments returns the comment lines written above a key in the sample files
+func comments(doc *fieldDoc) []string {
+	var lines []string
+	if doc.desc != "" {
+		lines = append(lines, doc.desc)
+	}
+	var notes []string
+	if _, ok := doc.rules["required"]; ok {
+		notes = append(notes, "required")
+	}
+	if oneof, ok := doc.rules["oneof"]; ok {
+		notes = append(notes, "one of: "+strings.Join(strings.Fields(oneof), ", "))
+	}
+	if doc.secret {
+		notes = append(notes, "secret, prefer ${env:...}, ${file:...} or ${enc:...}")
+	}
+	if len(notes) > 0 {
+		lines = append(lines, strings.Join(notes, "; "))
+	}
+	return lines
+}
+
+// SampleYAML generates a commented YAML config file with the defaults of the struct rawVal points to
+func SampleYAML(rawVal any) ([]byte, error) {
+	docs, err := describe(rawVal)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	writeYAML(&buf, docs, "")
+	return buf.Bytes(), nil
+}
+
+func writeYAML(buf *bytes.Buffer, docs []*fieldDoc, indent string) {
+	for _, doc := range docs {
+		for _, line := range comments(doc) {
+			fmt.Fprintf(buf, "%s# %s\n", indent, line)
+		}
+		switch {
+		case doc.typ.Kind() == reflect.Struct && doc.fields != nil:
+			fmt.Fprintf(buf, "%s%s:\n", indent, doc.name)
+			writeYAML(buf, doc.fields, indent+"  ")
+		case doc.typ.Kind() == reflect.Slice && doc.fields != nil:
+			fmt.Fprintf(buf, "%s%s:\n", indent, doc.name)
+			var item bytes.Buffer
+			writeYAML(&item, doc.fields, indent+"    ")
+			// the first key of the element starts the list item
+			lines := strings.SplitAfter(item.String(), "\n")
+			for i, line := range lines {
+				if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+					lines[i] = indent + "  - " + strings.TrimPrefix(line, indent+"    ")
+					break
+				}
+			}
+			buf.WriteString(strings.Join(lines, ""))
+		case doc.typ.Kind() == reflect.Map && doc.fields != nil:
+			fmt.Fprintf(buf, "%s%s:\n", indent, doc.name)
+			fmt.Fprintf(buf, "%s  example:\n", indent)
+			writeYAML(buf, doc.fields, indent+"    ")
+		default:
+			fmt.Fprintf(buf, "%s%s: %s\n", indent, doc.name, sampleValue(doc))
+		}
+	}
+}
+
+// SampleTOML generates a commented TOML config file with the defaults of the struct rawVal points to
+func SampleTOML(rawVal any) ([]byte, error) {
+	docs, err := describe(rawVal)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	writeTOML(&buf, docs)
+	return bytes.TrimLeft(buf.Bytes(), "\n"), nil
+}
+
+func writeTOML(buf *bytes.Buffer, docs []*fieldDoc) {
+	// plain keys must come before the tables of the same level
+	var tables []*fieldDoc
+	for _, doc := range docs {
+		if doc.fields != nil {
+			tables = append(tables, doc)
+			continue
+		}
+		for _, line := range comments(doc) {
+			fmt.Fprintf(buf, "# %s\n", line)
+		}
+		fmt.Fprintf(buf, "%s = %s\n", doc.name, sampleValue(doc))
+	}
+	for _, doc := range tables {
+		buf.WriteString("\n")
+		for _, line := range comments(doc) {
+			fmt.Fprintf(buf, "# %s\n", line)
+		}
+		switch doc.typ.Kind() {
+		case reflect.Slice:
+			fmt.Fprintf(buf, "[[%s]]\n", doc.key)
+		case reflect.Map:
+			fmt.Fprintf(buf, "[%s.example]\n", doc.key)
+		default:
+			fmt.Fprintf(buf, "[%s]\n", doc.key)
+		}
+		writeTOML(buf, doc.fields)
+	}
+}
+
+// Markdown generates a table of the keys of the struct rawVal points to with their type,
+// default, environment variable and description; envParts form the prefix like in IniWithEnv
+func Markdown(rawVal any, envParts ...string) (string, error) {
+	docs, err := describe(rawVal)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	buf.WriteString("| Key | Type | Default | Env | Description |\n")
+	buf.WriteString("| --- | --- | --- | --- | --- |\n")
+	writeMarkdown(&buf, docs, strings.Join(envParts, "_"), true)
+	return buf.String(), nil
+}
+
+func writeMarkdown(buf *bytes.Buffer, docs []*fieldDoc, prefix string, bindable bool) {
+	for _, doc := range docs {
+		if doc.typ.Kind() == reflect.Struct && doc.fields != nil {
+			writeMarkdown(buf, doc.fields, prefix, bindable)
+			continue
+		}
+
+		env := ""
+		switch {
+		case !bindable, doc.typ.Kind() == reflect.Slice && doc.fields != nil:
+		case doc.typ.Kind() == reflect.Map:
+			env = "`" + envName(prefix, doc.key) + EnvMapSep + "<KEY>`"
+		default:
+			env = "`" + envName(prefix, doc.key) + "`"
+		}
+		def := ""
+		if doc.hasDef {
+			def = "`" + doc.def + "`"
+		}
+		desc := strings.Join(comments(doc), "; ")
+		fmt.Fprintf(buf, "| `%s` | %s | %s | %s | %s |\n",
+			doc.key, typeName(doc.typ), def, env, strings.ReplaceAll(desc, "|", `\|`))
+
+		if doc.fields != nil {
+			writeMarkdown(buf, doc.fields, prefix, false)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+type DocConfig struct {
+	Common  `mapstructure:",squash"`
+	Mode    string            `mapstructure:"mode" default:"release" validate:"oneof=debug release" desc:"run mode"`
+	Port    int               `mapstructure:"port" default:"8080" validate:"required,min=1,max=65535" desc:"listen port"`
+	Timeout time.Duration     `mapstructure:"timeout" default:"30s" desc:"request timeout"`
+	Hosts   []string          `mapstructure:"hosts" default:"a,b"`
+	Ratio   float64           `mapstructure:"ratio" desc:"sample ratio | percent"`
+	Labels  map[string]string `mapstructure:"labels"`
+	DB      *DBConfig         `mapstructure:"db" desc:"database"`
+	Routes  []Backend         `mapstructure:"routes" desc:"upstream routes"`
+	Expires time.Time         `mapstructure:"expires" desc:"certificate expiry"`
+}
+
+func TestJSONSchema(t *testing.T) {
+	b, err := JSONSchema(&DocConfig{})
+	assert.NoError(t, err)
+
+	var schema map[string]any
+	assert.NoError(t, json.Unmarshal(b, &schema))
+	assert.Equal(t, "https://json-schema.org/draft/2020-12/schema", schema["$schema"])
+	assert.Equal(t, []any{"port"}, schema["required"])
+
+	props := schema["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{
+		"type": "integer", "default": float64(8080), "description": "listen port",
+		"minimum": float64(1), "maximum": float64(65535),
+	}, props["port"])
+	assert.Equal(t, []any{"debug", "release"}, props["mode"].(map[string]any)["enum"])
+	assert.Equal(t, map[string]any{
+		"type": "string", "format": "duration", "default": "30s", "description": "request timeout",
+	}, props["timeout"])
+	assert.Equal(t, []any{"a", "b"}, props["hosts"].(map[string]any)["default"])
+	assert.Equal(t, "cn", props["region"].(map[string]any)["default"])
+
+	db := props["db"].(map[string]any)
+	assert.Equal(t, []any{"host"}, db["required"])
+	dbProps := db["properties"].(map[string]any)
+	assert.Equal(t, true, dbProps["password"].(map[string]any)["writeOnly"])
+	routes := props["routes"].(map[string]any)
+	assert.Equal(t, "array", routes["type"])
+	assert.Contains(t, routes["items"].(map[string]any)["properties"], "url")
+	assert.Equal(t, map[string]any{"type": "string"}, props["labels"].(map[string]any)["additionalProperties"])
+
+	_, err = JSONSchema(3)
+	assert.Error(t, err)
+}
+
+func TestSampleFiles(t *testing.T) {
+	yml, err := SampleYAML(&DocConfig{})
+	assert.NoError(t, err)
+	assert.Contains(t, string(yml), "# listen port\n# required\nport: 8080\n")
+	assert.Contains(t, string(yml), "routes:\n  - url: \"\"\n")
+	assert.Contains(t, string(yml), "# certificate expiry\nexpires: \"0001-01-01T00:00:00Z\"\n")
+
+	toml, err := SampleTOML(&DocConfig{})
+	assert.NoError(t, err)
+	assert.Contains(t, string(toml), "# run mode\n# one of: debug, release\nmode = \"release\"\n")
+	assert.Contains(t, string(toml), "[[routes]]\nurl = \"\"\n")
+
+	for typ, content := range map[string][]byte{"yaml": yml, "toml": toml} {
+		v := viper.New()
+		v.SetConfigType(typ)
+		assert.NoError(t, v.ReadConfig(bytes.NewReader(content)), typ)
+
+		var cfg DocConfig
+		assert.NoError(t, v.Unmarshal(&cfg, decodeHook(nil)), typ)
+		assert.Equal(t, "release", cfg.Mode, typ)
+		assert.Equal(t, 8080, cfg.Port, typ)
+		assert.Equal(t, 30*time.Second, cfg.Timeout, typ)
+		assert.Equal(t, []string{"a", "b"}, cfg.Hosts, typ)
+		assert.Equal(t, 5432, cfg.DB.Port, typ)
+		assert.Equal(t, "cn", cfg.Region, typ)
+		assert.Len(t, cfg.Routes, 1, typ)
+		assert.True(t, cfg.Expires.IsZero(), typ)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	md, err := Markdown(&DocConfig{}, "app")
+	assert.NoError(t, err)
+	assert.Contains(t, md, "| Key | Type | Default | Env | Description |\n")
+	assert.Contains(t, md, "| `port` | integer | `8080` | `APP_PORT` | listen port; required |\n")
+	assert.Contains(t, md, "| `db.password` | string |  | `APP_DB_PASSWORD` | secret, prefer ${env:...}, ${file:...} or ${enc:...} |\n")
+	assert.Contains(t, md, "| `labels` | map of string |  | `APP_LABELS__<KEY>` |  |\n")
+	assert.Contains(t, md, "| `routes[].url` | string |  |  |  |\n")
+	assert.Contains(t, md, `sample ratio \| percent`)
+}
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/Jsharkc/mygopkg/crypto"
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/viper"
+)
+
+// EncKeyEnv is the environment variable holding the base64 AES key of ${enc:...} values
+const EncKeyEnv = "CONFIG_ENC_KEY"
+
+// placeholderRe matches ${scheme:ref} placeholders in config values
+var placeholderRe = regexp.MustCompile(`\$\{([a-z][a-z0-9_]*):([^}]*)\}`)
+
+// Resolver returns the value a ${scheme:ref} placeholder stands for
+type Resolver interface {
+	Resolve(ref string) (string, error)
+}
+
+// ResolverFunc adapts a function to Resolver
+type ResolverFunc func(ref string) (string, error)
+
+func (f ResolverFunc) Resolve(ref string) (string, error) {
+	return f(ref)
+}
+
+var (
+	resolversMu sync.RWMutex
+	resolvers   = map[string]Resolver{
+		"env":  ResolverFunc(resolveEnv),
+		"file": ResolverFunc(resolveFile),
+		"enc":  ResolverFunc(resolveEnc),
+	}
+)
+
+// RegisterResolver makes ${scheme:ref} placeholders resolve through r when loading any configuration,
+// env, file and enc are registered by default; a nil r removes the resolver of scheme
+func RegisterResolver(scheme string, r Resolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	if r == nil {
+		delete(resolvers, scheme)
+		return
+	}
+	resolvers[scheme] = r
+}
+
+func lookupResolver(scheme string, local map[string]Resolver) Resolver {
+	if r, ok := local[scheme]; ok {
+		return r
+	}
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+	return resolvers[scheme]
+}
+
+// ResolvePlaceholders replaces every ${scheme:ref} in s by the value of its resolver,
+// local resolvers take precedence over the registered ones
+func ResolvePlaceholders(s string, local map[string]Resolver) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	var errs []error
+	out := placeholderRe.ReplaceAllStringFunc(s, func(m string) string {
+		sub := placeholderRe.FindStringSubmatch(m)
+		r := lookupResolver(sub[1], local)
+		if r == nil {
+			errs = append(errs, fmt.Errorf("config: no resolver for ${%s:...}", sub[1]))
+			return m
+		}
+		v, err := r.Resolve(sub[2])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("config: resolve ${%s:...}: %w", sub[1], err))
+			return m
+		}
+		return v
+	})
+	if len(errs) > 0 {
+		return "", errors.Join(errs...)
+	}
+	return out, nil
+}
+
+// resolveHook resolves the placeholders of string values before they are decoded into fields
+func resolveHook(local map[string]Resolver) mapstructure.DecodeHookFunc {
+	return func(f reflect.Type, _ reflect.Type, data any) (any, error) {
+		if f.Kind() != reflect.String {
+			return data, nil
+		}
+		return ResolvePlaceholders(reflect.ValueOf(data).String(), local)
+	}
+}
+
+// placeholderKeys lists the keys of v whose raw value holds a ${scheme:ref} placeholder,
+// the resolved values are secrets and must not be logged
+func placeholderKeys(v *viper.Viper) []string {
+	var keys []string
+	for _, key := range v.AllKeys() {
+		if hasPlaceholder(v.Get(key)) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func hasPlaceholder(v any) bool {
+	switch v := v.(type) {
+	case string:
+		return placeholderRe.MatchString(v)
+	case []string:
+		for _, s := range v {
+			if placeholderRe.MatchString(s) {
+				return true
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if hasPlaceholder(item) {
+				return true
+			}
+		}
+	case map[string]any:
+		for _, item := range v {
+			if hasPlaceholder(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func resolveEnv(name string) (string, error) {
+	v, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return v, nil
+}
+
+// resolveFile reads a secret file such as a mounted Docker or Kubernetes secret,
+// the trailing newline is dropped
+func resolveFile(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+func resolveEnc(ref string) (string, error) {
+	key, err := EncKey()
+	if err != nil {
+		return "", err
+	}
+	return DecryptValue(ref, key)
+}
+
+// EncKey returns the key of ${enc:...} values from the CONFIG_ENC_KEY environment variable
+func EncKey() ([]byte, error) {
+	encoded := os.Getenv(EncKeyEnv)
+	if encoded == "" {
+		return nil, fmt.Errorf("%s is not set", EncKeyEnv)
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", EncKeyEnv, err)
+	}
+	return key, nil
+}
+
+// macSize is the length of the HMAC-SHA256 tag kept after the ciphertext
+const macSize = 16
+
+// valueMAC authenticates an encrypted value, keyed by a key derived from the AES key
+func valueMAC(key, data []byte) []byte {
+	macKey := crypto.HmacSha256("config enc mac", key)
+	return crypto.HmacSha256(string(data), macKey)[:macSize]
+}
+
+// EncryptValue encrypts plain with AES-CBC under key and returns the ${enc:...} placeholder
+// to put in a config file, holding the random IV, the ciphertext and a truncated HMAC-SHA256
+func EncryptValue(plain string, key []byte) (string, error) {
+	iv := make([]byte, 16)
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+	ciphertext, err := crypto.AesByteKeyEncrypt([]byte(plain), key, iv)
+	if err != nil {
+		return "", err
+	}
+	data := append(iv, ciphertext...)
+	data = append(data, valueMAC(key, data)...)
+	return "${enc:" + base64.StdEncoding.EncodeToString(data) + "}", nil
+}
+
+// DecryptValue decrypts the reference of an ${enc:...} placeholder made by EncryptValue
+func DecryptValue(ref string, key []byte) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(ref)
+	if err != nil {
+		return "", err
+	}
+	if len(b) < 32+macSize || (len(b)-macSize)%16 != 0 {
+		return "", errors.New("malformed encrypted value")
+	}
+	data, mac := b[:len(b)-macSize], b[len(b)-macSize:]
+	if !hmac.Equal(mac, valueMAC(key, data)) {
+		return "", errors.New("encrypted value does not match the key")
+	}
+	plain, err := crypto.AesByteKeyDecrypt(data[16:], key, data[:16])
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
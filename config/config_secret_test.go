@@ -0,0 +1,84 @@
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretPlaceholders(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	t.Setenv(EncKeyEnv, base64.StdEncoding.EncodeToString(key))
+	t.Setenv("TEST_DB_PASS", "from-env")
+
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "token")
+	assert.NoError(t, os.WriteFile(secret, []byte("abcdefgh\n"), 0600))
+	enc, err := EncryptValue("10.0.0.1", key)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(enc, "${enc:"))
+
+	writeConfig(t, dir, "server.yaml", `
+token: ${file:`+secret+`}
+mode: ${vault:mode}
+db:
+  host: `+enc+`
+  port: ${env:TEST_DB_PORT}
+  password: pre-${env:TEST_DB_PASS}
+`)
+	t.Setenv("TEST_DB_PORT", "6432")
+
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+	cfg, _, err := Load[ServerConfig](Options{
+		Name: "server", Type: "yaml", Paths: []string{dir},
+		Resolvers: map[string]Resolver{
+			"vault": ResolverFunc(func(ref string) (string, error) { return "debug", nil }),
+		},
+	})
+	assert.NoError(t, err)
+	// values coming from placeholders are masked in the logged dump
+	assert.Contains(t, logged.String(), `"host":"******"`)
+	assert.Contains(t, logged.String(), `"port":"******"`)
+	assert.Contains(t, logged.String(), `"mode":"******"`)
+	assert.NotContains(t, logged.String(), "10.0.0.1")
+	assert.NotContains(t, logged.String(), "from-env")
+	assert.Equal(t, "abcdefgh", cfg.Token)
+	assert.Equal(t, "debug", cfg.Mode)
+	assert.Equal(t, "10.0.0.1", cfg.DB.Host)
+	assert.Equal(t, 6432, cfg.DB.Port)
+	assert.Equal(t, "pre-from-env", cfg.DB.Password)
+
+	// unknown schemes and failing resolvers fail the load
+	_, _, err = Load[ServerConfig](Options{Name: "server", Type: "yaml", Paths: []string{dir}})
+	assert.ErrorContains(t, err, "no resolver for ${vault:...}")
+
+	RegisterResolver("vault", ResolverFunc(func(string) (string, error) { return "", errors.New("sealed") }))
+	defer RegisterResolver("vault", nil)
+	_, _, err = Load[ServerConfig](Options{Name: "server", Type: "yaml", Paths: []string{dir}})
+	assert.ErrorContains(t, err, "sealed")
+}
+
+func TestEncryptValue(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	enc, err := EncryptValue("s3cret", key)
+	assert.NoError(t, err)
+	ref := strings.TrimSuffix(strings.TrimPrefix(enc, "${enc:"), "}")
+
+	plain, err := DecryptValue(ref, key)
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cret", plain)
+
+	_, err = DecryptValue(ref, []byte("fedcba9876543210"))
+	assert.Error(t, err)
+	_, err = DecryptValue("c2hvcnQ=", key)
+	assert.Error(t, err)
+}
@@ -0,0 +1,221 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// FieldError is a field of the configuration breaking a validate rule
+type FieldError struct {
+	// Path is the key of the field, e.g. db.replicas[1].port
+	Path  string
+	Rule  string
+	Param string
+	Value any
+}
+
+func (e *FieldError) Error() string {
+	switch e.Rule {
+	case "required":
+		return fmt.Sprintf("%s: is required", e.Path)
+	case "min":
+		return fmt.Sprintf("%s: must be at least %s, got %v", e.Path, e.Param, e.Value)
+	case "max":
+		return fmt.Sprintf("%s: must be at most %s, got %v", e.Path, e.Param, e.Value)
+	case "len":
+		return fmt.Sprintf("%s: length must be %s, got %v", e.Path, e.Param, e.Value)
+	case "oneof":
+		return fmt.Sprintf("%s: must be one of [%s], got %v", e.Path, e.Param, e.Value)
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Rule)
+}
+
+// ValidationErrors holds every rule broken by a configuration
+type ValidationErrors []*FieldError
+
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return "config: invalid configuration: " + strings.Join(msgs, "; ")
+}
+
+// Validate checks the fields of the struct pointed to by v against their validate tags:
+//
+//	required    non-zero value
+//	min=N       numbers at least N, strings, slices and maps at least N long
+//	max=N       numbers at most N, strings, slices and maps at most N long
+//	len=N       strings, slices and maps exactly N long
+//	oneof=a b   one of the space separated values
+//
+// Durations take their parameters as durations, e.g. min=1s.
+// Nested structs, pointers, slices and maps are walked, the error is a ValidationErrors
+func Validate(v any) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return fmt.Errorf("config: cannot validate nil %T", v)
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("config: cannot validate %T, need a struct", v)
+	}
+
+	var errs ValidationErrors
+	validateStruct(rv, "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateStruct(rv reflect.Value, prefix string, errs *ValidationErrors) {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key, squash, ok := fieldKey(field)
+		if !ok {
+			continue
+		}
+		path := joinKey(prefix, key)
+		if squash {
+			path = prefix
+		}
+
+		fv := rv.Field(i)
+		if tag := field.Tag.Get("validate"); tag != "" {
+			for _, rule := range strings.Split(tag, ",") {
+				name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+				if err := checkRule(fv, name, param); err != nil {
+					err.Path = path
+					*errs = append(*errs, err)
+				}
+			}
+		}
+		validateValue(fv, path, errs)
+	}
+}
+
+// validateValue walks into the values that may hold tagged structs
+func validateValue(rv reflect.Value, path string, errs *ValidationErrors) {
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !rv.IsNil() {
+			validateValue(rv.Elem(), path, errs)
+		}
+	case reflect.Struct:
+		if !isLeafType(rv.Type()) {
+			validateStruct(rv, path, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			validateValue(rv.Index(i), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case reflect.Map:
+		iter := rv.MapRange()
+		for iter.Next() {
+			validateValue(iter.Value(), joinKey(path, fmt.Sprint(iter.Key().Interface())), errs)
+		}
+	}
+}
+
+func checkRule(rv reflect.Value, rule, param string) *FieldError {
+	fail := func(value any) *FieldError {
+		return &FieldError{Rule: rule, Param: param, Value: value}
+	}
+
+	if rule == "required" {
+		if isEmpty(rv) {
+			return fail(nil)
+		}
+		return nil
+	}
+
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			// unset optional values are left to required
+			return nil
+		}
+		rv = rv.Elem()
+	}
+
+	switch rule {
+	case "min", "max", "len":
+		got, limit, err := measure(rv, param)
+		if err != nil {
+			return &FieldError{Rule: fmt.Sprintf("bad %s rule: %v", rule, err)}
+		}
+		switch {
+		case rule == "min" && got < limit,
+			rule == "max" && got > limit,
+			rule == "len" && got != limit:
+			if rv.Type() == durationType {
+				return fail(rv.Interface())
+			}
+			return fail(formatMeasure(rv, got))
+		}
+	case "oneof":
+		value := fmt.Sprint(rv.Interface())
+		for _, allowed := range strings.Fields(param) {
+			if value == allowed {
+				return nil
+			}
+		}
+		return fail(rv.Interface())
+	default:
+		return &FieldError{Rule: fmt.Sprintf("unknown rule %q", rule)}
+	}
+	return nil
+}
+
+// measure returns the number compared by min, max and len, and the limit parsed from param
+func measure(rv reflect.Value, param string) (float64, float64, error) {
+	if rv.Type() == durationType {
+		limit, err := time.ParseDuration(param)
+		return float64(rv.Int()), float64(limit), err
+	}
+
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return float64(rv.Len()), limit, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), limit, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), limit, nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), limit, nil
+	}
+	return 0, 0, fmt.Errorf("unsupported type %s", rv.Type())
+}
+
+func formatMeasure(rv reflect.Value, got float64) any {
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return fmt.Sprintf("length %d", int(got))
+	}
+	return rv.Interface()
+}
+
+func isEmpty(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return rv.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return rv.IsNil()
+	}
+	return rv.IsZero()
+}
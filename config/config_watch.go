@@ -0,0 +1,260 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDelay groups the burst of events editors produce when saving a file
+const reloadDelay = 100 * time.Millisecond
+
+// Change describes a reload that modified the configuration
+type Change[T any] struct {
+	Old *T
+	New *T
+	// Keys lists the keys whose value changed, e.g. db.port, sorted
+	Keys []string
+}
+
+// Watcher keeps a configuration loaded by Load up to date with its files,
+// a reload that fails to load or validate keeps the previous configuration
+type Watcher[T any] struct {
+	opts    Options
+	current atomic.Pointer[T]
+
+	// serializes reloads
+	reloadMu sync.Mutex
+	// guards the subscribers, never held while calling them
+	mu        sync.Mutex
+	subs      map[int]func(Change[T])
+	errSubs   map[int]func(error)
+	nextSubID int
+
+	fsWatcher *fsnotify.Watcher
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewWatcher loads the configuration and starts watching the directories in opts.Paths
+// for changes to the config files, the first load must succeed
+func NewWatcher[T any](opts Options) (*Watcher[T], error) {
+	if len(opts.Paths) == 0 {
+		return nil, errors.New("config: watcher needs at least one config path")
+	}
+	cfg, _, err := Load[T](opts)
+	if err != nil {
+		return nil, err
+	}
+
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("config: watch: %w", err)
+	}
+	for _, dir := range opts.Paths {
+		if err := fsWatcher.Add(dir); err != nil {
+			_ = fsWatcher.Close()
+			return nil, fmt.Errorf("config: watch %s: %w", dir, err)
+		}
+	}
+
+	w := &Watcher[T]{
+		opts:      opts,
+		subs:      make(map[int]func(Change[T])),
+		errSubs:   make(map[int]func(error)),
+		fsWatcher: fsWatcher,
+		done:      make(chan struct{}),
+	}
+	w.current.Store(cfg)
+	w.wg.Add(1)
+	go w.run()
+	return w, nil
+}
+
+// Get returns the current configuration, it must not be modified
+func (w *Watcher[T]) Get() *T {
+	return w.current.Load()
+}
+
+// Subscribe calls fn after every reload that changed the configuration,
+// the returned function cancels the subscription
+func (w *Watcher[T]) Subscribe(fn func(Change[T])) (cancel func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	id := w.nextSubID
+	w.nextSubID++
+	w.subs[id] = fn
+	return func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.subs, id)
+	}
+}
+
+// OnError calls fn with the error of every rejected reload,
+// the returned function cancels the subscription
+func (w *Watcher[T]) OnError(fn func(error)) (cancel func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	id := w.nextSubID
+	w.nextSubID++
+	w.errSubs[id] = fn
+	return func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.errSubs, id)
+	}
+}
+
+// Reload loads the configuration again, on success the new value replaces the current
+// one and subscribers are notified if any key changed; on failure the current value is kept
+func (w *Watcher[T]) Reload() error {
+	change, err := w.reload()
+	if err != nil {
+		w.notifyError(err)
+		return err
+	}
+	if len(change.Keys) == 0 {
+		return nil
+	}
+
+	w.mu.Lock()
+	subs := make([]func(Change[T]), 0, len(w.subs))
+	for _, fn := range w.subs {
+		subs = append(subs, fn)
+	}
+	w.mu.Unlock()
+
+	for _, fn := range subs {
+		fn(change)
+	}
+	return nil
+}
+
+// reload loads and swaps in the new configuration, the change has no keys
+// when nothing changed
+func (w *Watcher[T]) reload() (Change[T], error) {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	cfg, _, err := Load[T](w.opts)
+	if err != nil {
+		return Change[T]{}, err
+	}
+
+	old := w.current.Load()
+	keys := changedKeys(toMap(old, false), toMap(cfg, false))
+	if len(keys) == 0 {
+		return Change[T]{}, nil
+	}
+	w.current.Store(cfg)
+	return Change[T]{Old: old, New: cfg, Keys: keys}, nil
+}
+
+// notifyError calls the OnError subscribers with err
+func (w *Watcher[T]) notifyError(err error) {
+	w.mu.Lock()
+	subs := make([]func(error), 0, len(w.errSubs))
+	for _, fn := range w.errSubs {
+		subs = append(subs, fn)
+	}
+	w.mu.Unlock()
+
+	for _, fn := range subs {
+		fn(err)
+	}
+}
+
+// Close stops watching the files
+func (w *Watcher[T]) Close() error {
+	var err error
+	w.closeOnce.Do(func() {
+		close(w.done)
+		err = w.fsWatcher.Close()
+		w.wg.Wait()
+	})
+	return err
+}
+
+func (w *Watcher[T]) run() {
+	defer w.wg.Done()
+
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case event, ok := <-w.fsWatcher.Events:
+			if !ok {
+				return
+			}
+			if w.isConfigEvent(event) {
+				timer.Reset(reloadDelay)
+			}
+		case err, ok := <-w.fsWatcher.Errors:
+			if !ok {
+				return
+			}
+			w.notifyError(fmt.Errorf("config: watch: %w", err))
+		case <-timer.C:
+			_ = w.Reload()
+		}
+	}
+}
+
+// isConfigEvent reports events writing one of the config files; removals are ignored so a
+// file replaced by rename is read once the new one exists, and "..data" is the symlink
+// Kubernetes swaps when a mounted ConfigMap changes
+func (w *Watcher[T]) isConfigEvent(event fsnotify.Event) bool {
+	if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+		return false
+	}
+	name := filepath.Base(event.Name)
+	return name == "..data" || name == w.opts.Name || strings.HasPrefix(name, w.opts.Name+".")
+}
+
+// changedKeys returns the keys whose values differ between two maps built by toMap,
+// nested maps are compared key by key and anything else as a whole
+func changedKeys(old, cur map[string]any) []string {
+	var keys []string
+	diffMaps("", old, cur, &keys)
+	sort.Strings(keys)
+	return keys
+}
+
+func diffMaps(prefix string, old, cur map[string]any, keys *[]string) {
+	for k, ov := range old {
+		key := joinKey(prefix, k)
+		cv, ok := cur[k]
+		if !ok {
+			*keys = append(*keys, key)
+			continue
+		}
+		om, oIsMap := ov.(map[string]any)
+		cm, cIsMap := cv.(map[string]any)
+		if oIsMap && cIsMap {
+			diffMaps(key, om, cm, keys)
+			continue
+		}
+		if !reflect.DeepEqual(ov, cv) {
+			*keys = append(*keys, key)
+		}
+	}
+	for k := range cur {
+		if _, ok := old[k]; !ok {
+			*keys = append(*keys, joinKey(prefix, k))
+		}
+	}
+}
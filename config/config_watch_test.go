@@ -0,0 +1,103 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "server.yaml", `
+token: abcdefgh
+db:
+  host: localhost
+  password: old
+`)
+
+	w, err := NewWatcher[ServerConfig](Options{Name: "server", Type: "yaml", Paths: []string{dir}})
+	assert.NoError(t, err)
+	defer w.Close()
+	first := w.Get()
+	assert.Equal(t, 5432, first.DB.Port)
+
+	changes := make(chan Change[ServerConfig], 4)
+	errs := make(chan error, 4)
+	w.Subscribe(func(c Change[ServerConfig]) { changes <- c })
+	w.OnError(func(err error) { errs <- err })
+
+	writeConfig(t, dir, "server.yaml", `
+token: abcdefgh
+mode: debug
+db:
+  host: localhost
+  port: 6000
+  password: new
+`)
+	select {
+	case c := <-changes:
+		assert.Same(t, first, c.Old)
+		assert.Same(t, w.Get(), c.New)
+		assert.Equal(t, []string{"db.password", "db.port", "mode"}, c.Keys)
+		assert.Equal(t, 6000, w.Get().DB.Port)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change notification")
+	}
+
+	// invalid files are rejected and the last good configuration stays
+	good := w.Get()
+	writeConfig(t, dir, "server.yaml", "token: short\n")
+	select {
+	case err := <-errs:
+		assert.ErrorContains(t, err, "token: length must be 8")
+	case <-time.After(5 * time.Second):
+		t.Fatal("no error notification")
+	}
+	assert.Same(t, good, w.Get())
+
+	assert.Error(t, w.Reload())
+	assert.NoError(t, w.Close())
+	assert.NoError(t, w.Close())
+}
+
+func TestChangedKeys(t *testing.T) {
+	old := map[string]any{"a": 1, "b": map[string]any{"c": []any{1}, "d": "x"}, "gone": true}
+	cur := map[string]any{"a": 1, "b": map[string]any{"c": []any{1, 2}, "d": "x"}, "new": true}
+	assert.Equal(t, []string{"b.c", "gone", "new"}, changedKeys(old, cur))
+	assert.Empty(t, changedKeys(old, old))
+}
+
+func TestWatcherCallbacksMaySubscribe(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "server.yaml", "token: abcdefgh\ndb:\n  host: localhost\n")
+	w, err := NewWatcher[ServerConfig](Options{Name: "server", Type: "yaml", Paths: []string{dir}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer w.Close()
+
+	// callbacks run without the watcher lock, so they can manage subscriptions
+	done := make(chan struct{}, 4)
+	var cancel func()
+	cancel = w.Subscribe(func(Change[ServerConfig]) {
+		cancel()
+		done <- struct{}{}
+	})
+	w.OnError(func(error) {
+		w.OnError(func(error) {})()
+		done <- struct{}{}
+	})
+
+	writeConfig(t, dir, "server.yaml", "token: abcdefgh\nmode: debug\ndb:\n  host: localhost\n")
+	assert.NoError(t, w.Reload())
+	writeConfig(t, dir, "server.yaml", "token: short\n")
+	assert.Error(t, w.Reload())
+	for i := 0; i < 2; i++ {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("callback did not return")
+		}
+	}
+}
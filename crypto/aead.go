@@ -0,0 +1,156 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// AeadAlg 认证加密算法，写入信封头部
+type AeadAlg byte
+
+const (
+	// AeadAesGcm AES-GCM，密钥长度 16、24 或 32 字节
+	AeadAesGcm AeadAlg = 1
+	// AeadChaCha20Poly1305 ChaCha20-Poly1305，密钥长度 32 字节
+	AeadChaCha20Poly1305 AeadAlg = 2
+)
+
+// EnvelopeVersion 信封格式版本：版本(1字节) | 算法(1字节) | nonce | 密文和认证标签
+const EnvelopeVersion = 1
+
+var (
+	ErrCiphertextTooShort = errors.New("密文长度不足")
+	ErrUnknownAeadAlg     = errors.New("未知的认证加密算法")
+	ErrEnvelopeVersion    = errors.New("不支持的信封版本")
+)
+
+func (alg AeadAlg) String() string {
+	switch alg {
+	case AeadAesGcm:
+		return "AES-GCM"
+	case AeadChaCha20Poly1305:
+		return "ChaCha20-Poly1305"
+	}
+	return fmt.Sprintf("AeadAlg(%d)", byte(alg))
+}
+
+// NewAead 按算法和密钥创建 cipher.AEAD
+func NewAead(alg AeadAlg, key []byte) (cipher.AEAD, error) {
+	switch alg {
+	case AeadAesGcm:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case AeadChaCha20Poly1305:
+		return chacha20poly1305.New(key)
+	}
+	return nil, ErrUnknownAeadAlg
+}
+
+// aeadSeal 使用随机 nonce 加密，nonce 放在密文前面
+func aeadSeal(aead cipher.AEAD, prefix, plain, ad []byte) ([]byte, error) {
+	out := make([]byte, len(prefix)+aead.NonceSize(), len(prefix)+aead.NonceSize()+len(plain)+aead.Overhead())
+	copy(out, prefix)
+	nonce := out[len(prefix):]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(out, nonce, plain, ad), nil
+}
+
+// aeadOpen 解密 nonce 在前的密文
+func aeadOpen(aead cipher.AEAD, ciphertext, ad []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrCiphertextTooShort
+	}
+	nonce, data := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, data, ad)
+}
+
+// AesGcmEncrypt AES-GCM 加密，返回 nonce 在前的密文
+// ad 为附加认证数据，不加密但参与认证，解密时必须相同，可以为 nil
+func AesGcmEncrypt(plain, key, ad []byte) ([]byte, error) {
+	aead, err := NewAead(AeadAesGcm, key)
+	if err != nil {
+		return nil, err
+	}
+	return aeadSeal(aead, nil, plain, ad)
+}
+
+// AesGcmDecrypt AES-GCM 解密 AesGcmEncrypt 的结果
+func AesGcmDecrypt(ciphertext, key, ad []byte) ([]byte, error) {
+	aead, err := NewAead(AeadAesGcm, key)
+	if err != nil {
+		return nil, err
+	}
+	return aeadOpen(aead, ciphertext, ad)
+}
+
+// ChaCha20Poly1305Encrypt ChaCha20-Poly1305 加密，返回 nonce 在前的密文
+func ChaCha20Poly1305Encrypt(plain, key, ad []byte) ([]byte, error) {
+	aead, err := NewAead(AeadChaCha20Poly1305, key)
+	if err != nil {
+		return nil, err
+	}
+	return aeadSeal(aead, nil, plain, ad)
+}
+
+// ChaCha20Poly1305Decrypt ChaCha20-Poly1305 解密 ChaCha20Poly1305Encrypt 的结果
+func ChaCha20Poly1305Decrypt(ciphertext, key, ad []byte) ([]byte, error) {
+	aead, err := NewAead(AeadChaCha20Poly1305, key)
+	if err != nil {
+		return nil, err
+	}
+	return aeadOpen(aead, ciphertext, ad)
+}
+
+// SealEnvelope 加密并生成带版本和算法的信封，头部同样受认证保护
+func SealEnvelope(alg AeadAlg, key, plain, ad []byte) ([]byte, error) {
+	aead, err := NewAead(alg, key)
+	if err != nil {
+		return nil, err
+	}
+	header := []byte{EnvelopeVersion, byte(alg)}
+	return aeadSeal(aead, header, plain, envelopeAd(header, ad))
+}
+
+// OpenEnvelope 解密 SealEnvelope 生成的信封，算法从头部读取
+func OpenEnvelope(key, envelope, ad []byte) ([]byte, error) {
+	alg, err := EnvelopeAlg(envelope)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := NewAead(alg, key)
+	if err != nil {
+		return nil, err
+	}
+	header := envelope[:2]
+	return aeadOpen(aead, envelope[2:], envelopeAd(header, ad))
+}
+
+// EnvelopeAlg 返回信封使用的算法
+func EnvelopeAlg(envelope []byte) (AeadAlg, error) {
+	if len(envelope) < 2 {
+		return 0, ErrCiphertextTooShort
+	}
+	if envelope[0] != EnvelopeVersion {
+		return 0, ErrEnvelopeVersion
+	}
+	alg := AeadAlg(envelope[1])
+	if alg != AeadAesGcm && alg != AeadChaCha20Poly1305 {
+		return 0, ErrUnknownAeadAlg
+	}
+	return alg, nil
+}
+
+// envelopeAd 把头部加入附加认证数据，防止篡改版本和算法
+func envelopeAd(header, ad []byte) []byte {
+	return append(append([]byte(nil), header...), ad...)
+}
@@ -0,0 +1,115 @@
+package crypto_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/Jsharkc/mygopkg/crypto"
+)
+
+func TestAead(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	plain := []byte("hello aead")
+	ad := []byte("user-1")
+
+	cases := map[string][2]func(a, b, c []byte) ([]byte, error){
+		"gcm":    {crypto.AesGcmEncrypt, crypto.AesGcmDecrypt},
+		"chacha": {crypto.ChaCha20Poly1305Encrypt, crypto.ChaCha20Poly1305Decrypt},
+	}
+	for name, c := range cases {
+		encrypted, err := c[0](plain, key, ad)
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		again, _ := c[0](plain, key, ad)
+		if bytes.Equal(encrypted, again) {
+			t.Error(name, "nonce must be random")
+		}
+		decrypted, err := c[1](encrypted, key, ad)
+		if err != nil || !bytes.Equal(decrypted, plain) {
+			t.Error(name, "decrypt", err)
+		}
+		if _, err := c[1](encrypted, key, []byte("user-2")); err == nil {
+			t.Error(name, "associated data must be authenticated")
+		}
+		encrypted[len(encrypted)-1] ^= 1
+		if _, err := c[1](encrypted, key, ad); err == nil {
+			t.Error(name, "tampered ciphertext must fail")
+		}
+		if _, err := c[1](encrypted[:4], key, ad); !errors.Is(err, crypto.ErrCiphertextTooShort) {
+			t.Error(name, "short ciphertext", err)
+		}
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	key := bytes.Repeat([]byte{9}, 32)
+	for _, alg := range []crypto.AeadAlg{crypto.AeadAesGcm, crypto.AeadChaCha20Poly1305} {
+		envelope, err := crypto.SealEnvelope(alg, key, []byte("secret"), nil)
+		if err != nil {
+			t.Fatal(alg, err)
+		}
+		if got, _ := crypto.EnvelopeAlg(envelope); got != alg || envelope[0] != crypto.EnvelopeVersion {
+			t.Error(alg, "header")
+		}
+		plain, err := crypto.OpenEnvelope(key, envelope, nil)
+		if err != nil || string(plain) != "secret" {
+			t.Error(alg, "open", err)
+		}
+
+		// 修改头部中的算法必须被发现
+		swapped := append([]byte(nil), envelope...)
+		swapped[1] = byte(crypto.AeadAesGcm + crypto.AeadChaCha20Poly1305 - alg)
+		if _, err := crypto.OpenEnvelope(key, swapped, nil); err == nil {
+			t.Error(alg, "swapped algorithm must fail")
+		}
+	}
+
+	if _, err := crypto.OpenEnvelope(key, []byte{2, 1, 0}, nil); !errors.Is(err, crypto.ErrEnvelopeVersion) {
+		t.Error("version", err)
+	}
+	if _, err := crypto.SealEnvelope(crypto.AeadAlg(9), key, nil, nil); !errors.Is(err, crypto.ErrUnknownAeadAlg) {
+		t.Error("alg", err)
+	}
+}
+
+func TestAesCbcPadding(t *testing.T) {
+	key := []byte("abcdefghigklmnop")
+	iv := []byte("1234567890abcdef")
+
+	buf := make([]byte, 3, 32)
+	copy(buf, "abc")
+	encrypted, err := crypto.AesByteKeyEncrypt(buf, key, iv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf[:cap(buf)][3:4]) != "\x00" {
+		t.Error("input must not be modified")
+	}
+
+	if _, err := crypto.AesByteKeyDecrypt(nil, key, iv); !errors.Is(err, crypto.ErrInvalidCiphertext) {
+		t.Error("empty", err)
+	}
+	if _, err := crypto.AesByteKeyDecrypt(encrypted[:5], key, iv); !errors.Is(err, crypto.ErrInvalidCiphertext) {
+		t.Error("partial block", err)
+	}
+	if _, err := crypto.AesByteKeyDecrypt(encrypted, key, iv[:8]); !errors.Is(err, crypto.ErrInvalidIV) {
+		t.Error("iv", err)
+	}
+	if _, err := crypto.AesByteKeyEncrypt(buf, key, iv[:8]); !errors.Is(err, crypto.ErrInvalidIV) {
+		t.Error("encrypt iv", err)
+	}
+
+	// 错误的密钥得到无效填充，返回错误而不是 panic
+	invalid := 0
+	for i := 0; i < 50; i++ {
+		wrong := bytes.Repeat([]byte{byte(i)}, 16)
+		if _, err := crypto.AesByteKeyDecrypt(encrypted, wrong, iv); errors.Is(err, crypto.ErrInvalidPadding) {
+			invalid++
+		}
+	}
+	if invalid < 40 {
+		t.Error("padding is not validated", invalid)
+	}
+}
@@ -0,0 +1,214 @@
+package crypto
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+// BLAKE3 哈希模式的实现（无密钥、32 字节输出），按规范的参考实现编写，未做 SIMD 优化
+const (
+	blake3BlockLen = 64
+	blake3ChunkLen = 1024
+
+	blake3ChunkStart = 1 << 0
+	blake3ChunkEnd   = 1 << 1
+	blake3Parent     = 1 << 2
+	blake3Root       = 1 << 3
+)
+
+var blake3IV = [8]uint32{
+	0x6A09E667, 0xBB67AE85, 0x3C6EF372, 0xA54FF53A,
+	0x510E527F, 0x9B05688C, 0x1F83D9AB, 0x5BE0CD19,
+}
+
+var blake3Permutation = [16]int{2, 6, 3, 10, 7, 0, 4, 13, 1, 11, 12, 5, 9, 14, 15, 8}
+
+func blake3G(s *[16]uint32, a, b, c, d int, mx, my uint32) {
+	s[a] = s[a] + s[b] + mx
+	s[d] = bits.RotateLeft32(s[d]^s[a], -16)
+	s[c] = s[c] + s[d]
+	s[b] = bits.RotateLeft32(s[b]^s[c], -12)
+	s[a] = s[a] + s[b] + my
+	s[d] = bits.RotateLeft32(s[d]^s[a], -8)
+	s[c] = s[c] + s[d]
+	s[b] = bits.RotateLeft32(s[b]^s[c], -7)
+}
+
+// blake3Compress 压缩函数，返回完整的 16 个字
+func blake3Compress(cv *[8]uint32, block *[16]uint32, counter uint64, blockLen, flags uint32) [16]uint32 {
+	s := [16]uint32{
+		cv[0], cv[1], cv[2], cv[3], cv[4], cv[5], cv[6], cv[7],
+		blake3IV[0], blake3IV[1], blake3IV[2], blake3IV[3],
+		uint32(counter), uint32(counter >> 32), blockLen, flags,
+	}
+	m := *block
+	for round := 0; round < 7; round++ {
+		blake3G(&s, 0, 4, 8, 12, m[0], m[1])
+		blake3G(&s, 1, 5, 9, 13, m[2], m[3])
+		blake3G(&s, 2, 6, 10, 14, m[4], m[5])
+		blake3G(&s, 3, 7, 11, 15, m[6], m[7])
+		blake3G(&s, 0, 5, 10, 15, m[8], m[9])
+		blake3G(&s, 1, 6, 11, 12, m[10], m[11])
+		blake3G(&s, 2, 7, 8, 13, m[12], m[13])
+		blake3G(&s, 3, 4, 9, 14, m[14], m[15])
+		if round < 6 {
+			var p [16]uint32
+			for i, j := range blake3Permutation {
+				p[i] = m[j]
+			}
+			m = p
+		}
+	}
+	for i := 0; i < 8; i++ {
+		s[i] ^= s[i+8]
+		s[i+8] ^= cv[i]
+	}
+	return s
+}
+
+func blake3Words(b []byte) [16]uint32 {
+	var padded [blake3BlockLen]byte
+	copy(padded[:], b)
+	var w [16]uint32
+	for i := range w {
+		w[i] = binary.LittleEndian.Uint32(padded[i*4:])
+	}
+	return w
+}
+
+// blake3Output 尚未压缩的节点，最后决定是否作为根节点
+type blake3Output struct {
+	cv       [8]uint32
+	block    [16]uint32
+	counter  uint64
+	blockLen uint32
+	flags    uint32
+}
+
+func (o *blake3Output) chainingValue() [8]uint32 {
+	s := blake3Compress(&o.cv, &o.block, o.counter, o.blockLen, o.flags)
+	var cv [8]uint32
+	copy(cv[:], s[:8])
+	return cv
+}
+
+func (o *blake3Output) root() []byte {
+	s := blake3Compress(&o.cv, &o.block, 0, o.blockLen, o.flags|blake3Root)
+	out := make([]byte, 32)
+	for i := 0; i < 8; i++ {
+		binary.LittleEndian.PutUint32(out[i*4:], s[i])
+	}
+	return out
+}
+
+func blake3ParentOutput(left, right [8]uint32) blake3Output {
+	o := blake3Output{cv: blake3IV, blockLen: blake3BlockLen, flags: blake3Parent}
+	copy(o.block[:8], left[:])
+	copy(o.block[8:], right[:])
+	return o
+}
+
+// blake3Chunk 正在处理的 1024 字节分块
+type blake3Chunk struct {
+	cv         [8]uint32
+	counter    uint64
+	buf        [blake3BlockLen]byte
+	bufLen     int
+	compressed int
+}
+
+func newBlake3Chunk(counter uint64) blake3Chunk {
+	return blake3Chunk{cv: blake3IV, counter: counter}
+}
+
+func (c *blake3Chunk) len() int {
+	return c.compressed*blake3BlockLen + c.bufLen
+}
+
+func (c *blake3Chunk) startFlag() uint32 {
+	if c.compressed == 0 {
+		return blake3ChunkStart
+	}
+	return 0
+}
+
+func (c *blake3Chunk) update(p []byte) {
+	for len(p) > 0 {
+		// 缓冲区满且还有数据时才压缩，最后一块要带 CHUNK_END 标记
+		if c.bufLen == blake3BlockLen {
+			block := blake3Words(c.buf[:])
+			s := blake3Compress(&c.cv, &block, c.counter, blake3BlockLen, c.startFlag())
+			copy(c.cv[:], s[:8])
+			c.compressed++
+			c.bufLen = 0
+		}
+		n := copy(c.buf[c.bufLen:], p)
+		c.bufLen += n
+		p = p[n:]
+	}
+}
+
+func (c *blake3Chunk) output() blake3Output {
+	return blake3Output{
+		cv:       c.cv,
+		block:    blake3Words(c.buf[:c.bufLen]),
+		counter:  c.counter,
+		blockLen: uint32(c.bufLen),
+		flags:    c.startFlag() | blake3ChunkEnd,
+	}
+}
+
+type blake3 struct {
+	chunk blake3Chunk
+	stack [][8]uint32
+}
+
+// NewBlake3 创建 BLAKE3 哈希，输出 32 字节
+func NewBlake3() hash.Hash {
+	return &blake3{chunk: newBlake3Chunk(0)}
+}
+
+func (d *blake3) Size() int      { return 32 }
+func (d *blake3) BlockSize() int { return blake3BlockLen }
+
+func (d *blake3) Reset() {
+	d.chunk = newBlake3Chunk(0)
+	d.stack = d.stack[:0]
+}
+
+// pushChunk 完成的分块加入树中，totalChunks 每有一个末尾的 0 位就合并一次父节点
+func (d *blake3) pushChunk(cv [8]uint32, totalChunks uint64) {
+	for totalChunks&1 == 0 {
+		top := d.stack[len(d.stack)-1]
+		d.stack = d.stack[:len(d.stack)-1]
+		parent := blake3ParentOutput(top, cv)
+		cv = parent.chainingValue()
+		totalChunks >>= 1
+	}
+	d.stack = append(d.stack, cv)
+}
+
+func (d *blake3) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		if d.chunk.len() == blake3ChunkLen {
+			out := d.chunk.output()
+			counter := d.chunk.counter + 1
+			d.pushChunk(out.chainingValue(), counter)
+			d.chunk = newBlake3Chunk(counter)
+		}
+		take := min(blake3ChunkLen-d.chunk.len(), len(p))
+		d.chunk.update(p[:take])
+		p = p[take:]
+	}
+	return n, nil
+}
+
+func (d *blake3) Sum(b []byte) []byte {
+	out := d.chunk.output()
+	for i := len(d.stack) - 1; i >= 0; i-- {
+		out = blake3ParentOutput(d.stack[i], out.chainingValue())
+	}
+	return append(b, out.root()...)
+}
@@ -6,10 +6,10 @@ import (
 	"crypto/cipher"
 	"crypto/hmac"
 	"crypto/md5"
-	"crypto/rand"
 	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/sha512"
+	"crypto/subtle"
 	"encoding/base64"
 	"encoding/hex"
 	"errors"
@@ -17,6 +17,13 @@ import (
 	"os"
 )
 
+var (
+	ErrInvalidIV         = errors.New("iv 长度必须等于块大小")
+	ErrInvalidCiphertext = errors.New("密文长度必须是块大小的整数倍")
+	ErrInvalidPadding    = errors.New("填充无效")
+	ErrKeyNotFound       = errors.New("未找到密钥")
+)
+
 func Md5(text string) string {
 	hashMd5 := md5.New()
 	_, _ = io.WriteString(hashMd5, text)
@@ -31,6 +38,8 @@ func Md5Buf(buf []byte) string {
 
 // Md5File 计算文件内容的 md5
 // 请确保文件能正常打开，如果不能，返回文件路径的 md5
+//
+// Deprecated: 无法区分读取失败，请使用 DigestFile
 func Md5File(filename string) string {
 	f, err := os.Open(filename)
 	if err != nil {
@@ -41,6 +50,9 @@ func Md5File(filename string) string {
 	return Md5Stream(f)
 }
 
+// Md5Stream 计算 r 的 md5，读取出错时返回已读部分的结果
+//
+// Deprecated: 无法区分读取失败，请使用 DigestReader
 func Md5Stream(r io.Reader) string {
 	var buf = make([]byte, 4096)
 	hashMd5 := md5.New()
@@ -153,20 +165,12 @@ func AesGenerate(bits int) ([]byte, error) {
 	if bits != 128 && bits != 192 && bits != 256 {
 		return nil, errors.New("密钥长度必须为128，192，256中的某一个")
 	}
-	key, err := rand.Prime(rand.Reader, bits)
-	if err != nil {
-		return nil, err
-	}
-	return key.Bytes(), nil
+	return RandomBytes(bits / 8)
 }
 
 // AesIvGenerate 自动创建一个iv值
 func AesIvGenerate() ([]byte, error) {
-	key, err := rand.Prime(rand.Reader, 128)
-	if err != nil {
-		return nil, err
-	}
-	return key.Bytes(), nil
+	return RandomBytes(aes.BlockSize)
 }
 
 // AesEncrypt 使用binary string传递密钥和iv的方式（兼容旧实现）
@@ -197,11 +201,11 @@ func AesByteKeyEncrypt(buf, encryptKey, iv []byte) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	if len(iv) != aesBlockEncrypter.BlockSize() {
+		return nil, ErrInvalidIV
+	}
 	content := pkcs7Padding(buf, aesBlockEncrypter.BlockSize())
 	encrypted := make([]byte, len(content))
-	if err != nil {
-		return nil, err
-	}
 	aesEncrypter := cipher.NewCBCEncrypter(aesBlockEncrypter, iv)
 	aesEncrypter.CryptBlocks(encrypted, content)
 	return encrypted, nil
@@ -230,25 +234,49 @@ func AesBase64Decrypt(src []byte, base64EncryptKey string, base64iv string) (dat
 }
 
 // AesByteKeyDecrypt AES加密 buf：加密buf，encryptKey: 加密秘钥，iv：偏移量
+// 密文长度不是块大小的整数倍或填充无效时返回错误
 func AesByteKeyDecrypt(src []byte, encryptKey, iv []byte) (data []byte, err error) {
-	decrypted := make([]byte, len(src))
 	var aesBlockDecrypter cipher.Block
 	aesBlockDecrypter, err = aes.NewCipher(encryptKey)
 	if err != nil {
 		return nil, err
 	}
+	blockSize := aesBlockDecrypter.BlockSize()
+	if len(iv) != blockSize {
+		return nil, ErrInvalidIV
+	}
+	if len(src) == 0 || len(src)%blockSize != 0 {
+		return nil, ErrInvalidCiphertext
+	}
+	decrypted := make([]byte, len(src))
 	aesDecrypter := cipher.NewCBCDecrypter(aesBlockDecrypter, iv)
 	aesDecrypter.CryptBlocks(decrypted, src)
-	return pkcs7Trimming(decrypted), nil
+	return pkcs7Trimming(decrypted, blockSize)
 }
 
 func pkcs7Padding(buf []byte, blockSize int) []byte {
 	padding := blockSize - len(buf)%blockSize
 	padText := bytes.Repeat([]byte{byte(padding)}, padding)
-	return append(buf, padText...)
+	// 复制一份，避免 append 改写调用方切片的底层数组
+	return append(append(make([]byte, 0, len(buf)+padding), buf...), padText...)
 }
 
-func pkcs7Trimming(encrypt []byte) []byte {
-	padding := encrypt[len(encrypt)-1]
-	return encrypt[:len(encrypt)-int(padding)]
+// pkcs7Trimming 去除填充，检查填充长度和每个填充字节
+// 检查过程不提前返回，耗时与填充内容无关，避免泄露填充是否正确
+func pkcs7Trimming(encrypt []byte, blockSize int) ([]byte, error) {
+	if len(encrypt) == 0 || len(encrypt)%blockSize != 0 {
+		return nil, ErrInvalidPadding
+	}
+	padding := int(encrypt[len(encrypt)-1])
+	good := subtle.ConstantTimeLessOrEq(1, padding) & subtle.ConstantTimeLessOrEq(padding, blockSize)
+	for i := 1; i <= blockSize; i++ {
+		// 只检查最后 padding 个字节
+		inPadding := subtle.ConstantTimeLessOrEq(i, padding)
+		same := subtle.ConstantTimeByteEq(encrypt[len(encrypt)-i], byte(padding))
+		good &= same | (inPadding ^ 1)
+	}
+	if good != 1 {
+		return nil, ErrInvalidPadding
+	}
+	return encrypt[:len(encrypt)-padding], nil
 }
@@ -0,0 +1,149 @@
+package crypto
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+)
+
+// DigestAlg 摘要算法
+type DigestAlg string
+
+const (
+	DigestMd5      DigestAlg = "md5"
+	DigestSha1     DigestAlg = "sha1"
+	DigestSha256   DigestAlg = "sha256"
+	DigestSha512   DigestAlg = "sha512"
+	DigestCrc32c   DigestAlg = "crc32c"
+	DigestXxhash64 DigestAlg = "xxhash64"
+	DigestBlake3   DigestAlg = "blake3"
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// NewDigestHash 按算法创建 hash.Hash
+func NewDigestHash(alg DigestAlg) (hash.Hash, error) {
+	switch alg {
+	case DigestMd5:
+		return md5.New(), nil
+	case DigestSha1:
+		return sha1.New(), nil
+	case DigestSha256:
+		return sha256.New(), nil
+	case DigestSha512:
+		return sha512.New(), nil
+	case DigestCrc32c:
+		return crc32.New(crc32cTable), nil
+	case DigestXxhash64:
+		return NewXxhash64(), nil
+	case DigestBlake3:
+		return NewBlake3(), nil
+	}
+	return nil, fmt.Errorf("不支持的摘要算法 %s", alg)
+}
+
+// Sum 摘要结果，crc32c 和 xxhash64 为大端序
+type Sum []byte
+
+func (s Sum) Hex() string {
+	return hex.EncodeToString(s)
+}
+
+func (s Sum) Base64() string {
+	return base64.StdEncoding.EncodeToString(s)
+}
+
+func (s Sum) Base64URL() string {
+	return base64.RawURLEncoding.EncodeToString(s)
+}
+
+func (s Sum) String() string {
+	return s.Hex()
+}
+
+// Digests 各算法的摘要结果
+type Digests map[DigestAlg]Sum
+
+// Digest 同时计算多个摘要，写入的数据只需要读一遍
+type Digest struct {
+	algs   []DigestAlg
+	hashes []hash.Hash
+	w      io.Writer
+}
+
+// NewDigest 创建计算 algs 的 Digest
+func NewDigest(algs ...DigestAlg) (*Digest, error) {
+	d := &Digest{}
+	writers := make([]io.Writer, 0, len(algs))
+	for _, alg := range algs {
+		h, err := NewDigestHash(alg)
+		if err != nil {
+			return nil, err
+		}
+		d.algs = append(d.algs, alg)
+		d.hashes = append(d.hashes, h)
+		writers = append(writers, h)
+	}
+	d.w = io.MultiWriter(writers...)
+	return d, nil
+}
+
+func (d *Digest) Write(p []byte) (int, error) {
+	return d.w.Write(p)
+}
+
+// Sums 返回当前的摘要结果，之后还可以继续写入
+func (d *Digest) Sums() Digests {
+	sums := make(Digests, len(d.algs))
+	for i, alg := range d.algs {
+		sums[alg] = d.hashes[i].Sum(nil)
+	}
+	return sums
+}
+
+// Reset 清空已写入的数据
+func (d *Digest) Reset() {
+	for _, h := range d.hashes {
+		h.Reset()
+	}
+}
+
+// DigestReader 读取 r 一遍，计算 algs 的摘要
+func DigestReader(r io.Reader, algs ...DigestAlg) (Digests, error) {
+	d, err := NewDigest(algs...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(d, r); err != nil {
+		return nil, err
+	}
+	return d.Sums(), nil
+}
+
+// DigestFile 读取文件一遍，计算 algs 的摘要
+func DigestFile(filename string, algs ...DigestAlg) (Digests, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return DigestReader(f, algs...)
+}
+
+// DigestBytes 计算 buf 的 algs 摘要
+func DigestBytes(buf []byte, algs ...DigestAlg) (Digests, error) {
+	d, err := NewDigest(algs...)
+	if err != nil {
+		return nil, err
+	}
+	_, _ = d.Write(buf)
+	return d.Sums(), nil
+}
@@ -0,0 +1,101 @@
+package crypto_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Jsharkc/mygopkg/crypto"
+)
+
+// BLAKE3 官方测试向量的输入：第 i 个字节为 i % 251
+func testInput(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i % 251)
+	}
+	return b
+}
+
+func TestBlake3Xxhash64(t *testing.T) {
+	cases := []struct {
+		n        int
+		blake3   string
+		xxhash64 string
+	}{
+		{0, "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262", "ef46db3751d8e999"},
+		{1, "2d3adedff11b61f14c886e35afa036736dcd87a74d27b5c1510225d0f592e213", "e934a84adb052768"},
+		{63, "e9bc37a594daad83be9470df7f7b3798297c3d834ce80ba85d6e207627b7db7b", "e26aa9e2a95f8e4f"},
+		{64, "4eed7141ea4a5cd4b788606bd23f46e212af9cacebacdc7d1f4c6dc7f2511b98", "f7c67301db6713f0"},
+		{1024, "42214739f095a406f3fc83deb889744ac00df831c10daa55189b5d121c855af7", "138e26c65048ce29"},
+		{1025, "d00278ae47eb27b34faecf67b4fe263f82d5412916c1ffd97c8cb7fb814b8444", "cfd73aedd2d6a39d"},
+		{3072, "b98cb0ff3623be03326b373de6b9095218513e64f1ee2edd2525c7ad1e5cffd2", "278f56bcf5b542fe"},
+		{4097, "9b4052b38f1c5fc8b1f9ff7ac7b27cd242487b3d890d15c96a1c25b8aa0fb995", "ba236f554636de5b"},
+		{31745, "5c80ce0c3bbe9a6f432a1c6c2ccbde45923d23249386988a30f512d23919eb98", "b6215328c58e470e"},
+		{102400, "bc3e3d41a1146b069abffad3c0d44860cf664390afce4d9661f7902e7943e085", "eb1adcdd9e1369a6"},
+	}
+	for _, c := range cases {
+		input := testInput(c.n)
+		// 一次写入和分多次写入结果相同
+		for _, step := range []int{c.n + 1, 7, 1000} {
+			d, _ := crypto.NewDigest(crypto.DigestBlake3, crypto.DigestXxhash64)
+			for i := 0; i < len(input); i += step {
+				_, _ = d.Write(input[i:min(i+step, len(input))])
+			}
+			sums := d.Sums()
+			if got := sums[crypto.DigestBlake3].Hex(); got != c.blake3 {
+				t.Error(c.n, step, "blake3", got)
+			}
+			if got := sums[crypto.DigestXxhash64].Hex(); got != c.xxhash64 {
+				t.Error(c.n, step, "xxhash64", got)
+			}
+		}
+	}
+}
+
+func TestDigestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(path, []byte("123456"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	sums, err := crypto.DigestFile(path, crypto.DigestMd5, crypto.DigestSha1, crypto.DigestSha256, crypto.DigestSha512, crypto.DigestCrc32c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sums[crypto.DigestMd5].Hex() != crypto.Md5("123456") ||
+		sums[crypto.DigestSha1].Hex() != crypto.Sha1("123456") ||
+		sums[crypto.DigestSha256].Hex() != crypto.Sha256("123456") ||
+		sums[crypto.DigestSha512].Hex() != crypto.Sha512("123456") {
+		t.Error("sums", sums)
+	}
+	if got := sums[crypto.DigestCrc32c].Hex(); got != "41357186" {
+		t.Error("crc32c", got)
+	}
+	if sums[crypto.DigestSha256].Base64() != crypto.Sha256Base64("123456") {
+		t.Error("base64")
+	}
+	if got := sums[crypto.DigestMd5].Base64URL(); got != "4QrcOUm6Wau-VuBX8g-IPg" {
+		t.Error("base64url", got)
+	}
+
+	if _, err := crypto.DigestFile(filepath.Join(t.TempDir(), "missing"), crypto.DigestMd5); err == nil {
+		t.Error("missing file must fail")
+	}
+	if _, err := crypto.DigestBytes(nil, "md4"); err == nil {
+		t.Error("unknown algorithm must fail")
+	}
+	sums, _ = crypto.DigestBytes([]byte("123456"), crypto.DigestMd5)
+	if !bytes.Equal(sums[crypto.DigestMd5], mustDigest(t, "123456")) {
+		t.Error("bytes")
+	}
+}
+
+func mustDigest(t *testing.T, s string) crypto.Sum {
+	t.Helper()
+	sums, err := crypto.DigestReader(bytes.NewReader([]byte(s)), crypto.DigestMd5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sums[crypto.DigestMd5]
+}
@@ -0,0 +1,115 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"errors"
+)
+
+var (
+	ErrUnsupportedCurve = errors.New("只支持 P-256 和 P-384 曲线")
+	ErrSignature        = errors.New("签名验证失败")
+)
+
+// EcdsaGenerate 创建一组 ECDSA 密钥，返回 PKCS8 私钥和 PKIX 公钥
+// curve 为 elliptic.P256() 或 elliptic.P384()
+func EcdsaGenerate(curve elliptic.Curve) ([]byte, []byte, error) {
+	if curve != elliptic.P256() && curve != elliptic.P384() {
+		return nil, nil, ErrUnsupportedCurve
+	}
+	private, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return marshalKeyPair(private)
+}
+
+// Ed25519Generate 创建一组 Ed25519 密钥，返回 PKCS8 私钥和 PKIX 公钥
+func Ed25519Generate() ([]byte, []byte, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return marshalKeyPair(private)
+}
+
+func marshalKeyPair(private crypto.Signer) ([]byte, []byte, error) {
+	privateKey, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, nil, err
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return nil, nil, err
+	}
+	return privateKey, publicKey, nil
+}
+
+// ecdsaDigest 按曲线选择摘要算法：P-256 使用 SHA256，P-384 使用 SHA384
+func ecdsaDigest(curve elliptic.Curve, target []byte) ([]byte, error) {
+	switch curve {
+	case elliptic.P256():
+		sum := sha256.Sum256(target)
+		return sum[:], nil
+	case elliptic.P384():
+		sum := sha512.Sum384(target)
+		return sum[:], nil
+	}
+	return nil, ErrUnsupportedCurve
+}
+
+// EcdsaSign ECDSA 签名，返回 ASN.1 编码的签名
+func EcdsaSign(target, privateKey []byte) ([]byte, error) {
+	key, err := parseEcdsaPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := ecdsaDigest(key.Curve, target)
+	if err != nil {
+		return nil, err
+	}
+	return ecdsa.SignASN1(rand.Reader, key, digest)
+}
+
+// EcdsaVerify ECDSA 签名验证
+func EcdsaVerify(target, publicKey, sig []byte) error {
+	key, err := parseEcdsaPublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+	digest, err := ecdsaDigest(key.Curve, target)
+	if err != nil {
+		return err
+	}
+	if !ecdsa.VerifyASN1(key, digest, sig) {
+		return ErrSignature
+	}
+	return nil
+}
+
+// Ed25519Sign Ed25519 签名
+func Ed25519Sign(target, privateKey []byte) ([]byte, error) {
+	key, err := parseEd25519PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return ed25519.Sign(key, target), nil
+}
+
+// Ed25519Verify Ed25519 签名验证
+func Ed25519Verify(target, publicKey, sig []byte) error {
+	key, err := parseEd25519PublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(key, target, sig) {
+		return ErrSignature
+	}
+	return nil
+}
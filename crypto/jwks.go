@@ -0,0 +1,289 @@
+package crypto
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Jwk JSON Web Key 中的公钥字段
+type Jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC 和 OKP
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// Jwks JSON Web Key Set
+type Jwks struct {
+	Keys []Jwk `json:"keys"`
+}
+
+// Jwk 导出公钥，HS256 密钥不能公开，返回 ErrKeyType
+func (k *JwtKey) Jwk() (Jwk, error) {
+	jwk := Jwk{Kid: k.ID, Alg: k.Alg, Use: "sig"}
+	switch pub := k.Public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = jwtEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = jwtEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return jwk, ErrKeyType
+		}
+		jwk.Kty, jwk.Crv = "EC", "P-256"
+		jwk.X = jwtEncoding.EncodeToString(pub.X.FillBytes(make([]byte, 32)))
+		jwk.Y = jwtEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, 32)))
+	case ed25519.PublicKey:
+		jwk.Kty, jwk.Crv = "OKP", "Ed25519"
+		jwk.X = jwtEncoding.EncodeToString(pub)
+	default:
+		return jwk, ErrKeyType
+	}
+	return jwk, nil
+}
+
+// jwkMinRsaBits 远程 JWK 中 RSA 模数的最小位数
+const jwkMinRsaBits = 2048
+
+// JwtKey 解析 JWK 为验证密钥，RSA 模数少于 2048 位时返回错误
+func (j Jwk) JwtKey() (*JwtKey, error) {
+	key := &JwtKey{ID: j.Kid, Alg: j.Alg}
+	decode := func(s string) *big.Int {
+		b, err := jwtEncoding.DecodeString(s)
+		if err != nil || len(b) == 0 {
+			return nil
+		}
+		return new(big.Int).SetBytes(b)
+	}
+	switch {
+	case j.Kty == "RSA":
+		n, e := decode(j.N), decode(j.E)
+		if n == nil || e == nil || !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("JWK %s 的 RSA 参数无效", j.Kid)
+		}
+		if n.BitLen() < jwkMinRsaBits {
+			return nil, fmt.Errorf("JWK %s 的 RSA 模数只有 %d 位，至少需要 %d 位", j.Kid, n.BitLen(), jwkMinRsaBits)
+		}
+		key.Public = &rsa.PublicKey{N: n, E: int(e.Int64())}
+		if key.Alg == "" {
+			key.Alg = JwtRS256
+		}
+	case j.Kty == "EC" && j.Crv == "P-256":
+		x, y := decode(j.X), decode(j.Y)
+		if x == nil || y == nil || !elliptic.P256().IsOnCurve(x, y) {
+			return nil, fmt.Errorf("JWK %s 的 EC 参数无效", j.Kid)
+		}
+		key.Public = &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		if key.Alg == "" {
+			key.Alg = JwtES256
+		}
+	case j.Kty == "OKP" && j.Crv == "Ed25519":
+		x, err := jwtEncoding.DecodeString(j.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("JWK %s 的 Ed25519 参数无效", j.Kid)
+		}
+		key.Public = ed25519.PublicKey(x)
+		if key.Alg == "" {
+			key.Alg = JwtEdDSA
+		}
+	default:
+		return nil, fmt.Errorf("不支持的 JWK 类型 %s", j.Kty)
+	}
+	if err := checkJwtKey(key.Alg, key.Public); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Jwks 导出密钥集合中的公钥，HS256 密钥被跳过
+func (s JwtKeySet) Jwks() (Jwks, error) {
+	set := Jwks{Keys: []Jwk{}}
+	for _, k := range s {
+		if k.Alg == JwtHS256 {
+			continue
+		}
+		jwk, err := k.Jwk()
+		if err != nil {
+			return set, err
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set, nil
+}
+
+// ParseJwks 解析 JWKS 文档，不支持的密钥被跳过
+func ParseJwks(data []byte) (JwtKeySet, error) {
+	var set Jwks
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+	keys := make(JwtKeySet, 0, len(set.Keys))
+	for _, j := range set.Keys {
+		if j.Use != "" && j.Use != "sig" {
+			continue
+		}
+		key, err := j.JwtKey()
+		if err != nil {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// JwksHandler 以 JSON 发布密钥集合中的公钥，maxAge 为客户端缓存时间
+func JwksHandler(keys JwtKeySet, maxAge time.Duration) (http.Handler, error) {
+	set, err := keys.Jwks()
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(set)
+	if err != nil {
+		return nil, err
+	}
+	cacheControl := fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds()))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", cacheControl)
+		_, _ = w.Write(body)
+	}), nil
+}
+
+// jwksMinRefresh 遇到未知 kid 时两次刷新的最小间隔，防止被伪造的 kid 刷爆
+const jwksMinRefresh = 10 * time.Second
+
+// JwksClient 从 URL 获取并缓存 JWKS，实现 JwtKeySource
+// 缓存超过 TTL 时在后台重新获取，期间继续使用旧的缓存；遇到未知 kid 时等待重新获取，
+// 获取失败时继续使用旧的缓存
+type JwksClient struct {
+	URL    string
+	TTL    time.Duration
+	Client *http.Client
+
+	mu       sync.Mutex
+	keys     JwtKeySet
+	fetched  time.Time
+	fetching *jwksFetch
+}
+
+// jwksFetch 进行中的一次获取，并发的调用共享它的结果
+type jwksFetch struct {
+	done chan struct{}
+	err  error
+}
+
+// NewJwksClient 创建 JWKS 客户端
+func NewJwksClient(url string, ttl time.Duration) *JwksClient {
+	return &JwksClient{URL: url, TTL: ttl, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// JwtKey 按 kid 查找密钥
+func (c *JwksClient) JwtKey(kid string) (*JwtKey, error) {
+	keys, fetched := c.cached()
+	if keys == nil {
+		if err := c.refresh(); err != nil {
+			return nil, err
+		}
+		keys, fetched = c.cached()
+	} else if time.Since(fetched) > c.TTL {
+		c.mu.Lock()
+		c.startFetch()
+		c.mu.Unlock()
+	}
+
+	key, err := keys.JwtKey(kid)
+	if err == ErrKeyNotFound && time.Since(fetched) > jwksMinRefresh {
+		if err := c.refresh(); err != nil {
+			return nil, err
+		}
+		keys, _ = c.cached()
+		return keys.JwtKey(kid)
+	}
+	return key, err
+}
+
+// Refresh 立即重新获取 JWKS，已有进行中的获取时等待它的结果
+func (c *JwksClient) Refresh() error {
+	return c.refresh()
+}
+
+func (c *JwksClient) cached() (JwtKeySet, time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.keys, c.fetched
+}
+
+// refresh 发起或加入一次获取并等待结果
+func (c *JwksClient) refresh() error {
+	c.mu.Lock()
+	f := c.startFetch()
+	c.mu.Unlock()
+	<-f.done
+	return f.err
+}
+
+// startFetch 在后台获取 JWKS，已有进行中的获取时直接返回它，调用时需持有 c.mu
+func (c *JwksClient) startFetch() *jwksFetch {
+	if c.fetching != nil {
+		return c.fetching
+	}
+	f := &jwksFetch{done: make(chan struct{})}
+	c.fetching = f
+	c.fetched = time.Now()
+	go func() {
+		keys, err := c.fetch()
+		c.mu.Lock()
+		if err == nil {
+			c.keys = keys
+		}
+		c.fetching = nil
+		c.mu.Unlock()
+		f.err = err
+		close(f.done)
+	}()
+	return f
+}
+
+// fetch 请求 URL 并解析 JWKS，不持有 c.mu
+func (c *JwksClient) fetch() (JwtKeySet, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("获取 JWKS 失败: %s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	return ParseJwks(body)
+}
@@ -0,0 +1,370 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// JWT 签名算法
+const (
+	JwtHS256 = "HS256"
+	JwtRS256 = "RS256"
+	JwtES256 = "ES256"
+	JwtEdDSA = "EdDSA"
+)
+
+var (
+	ErrJwtMalformed    = errors.New("JWT 格式错误")
+	ErrJwtAlg          = errors.New("JWT 算法与密钥不匹配")
+	ErrJwtSignature    = errors.New("JWT 签名无效")
+	ErrJwtExpired      = errors.New("JWT 已过期")
+	ErrJwtNotYetValid  = errors.New("JWT 尚未生效")
+	ErrJwtIssuedAt     = errors.New("JWT 签发时间晚于当前时间")
+	ErrJwtIssuer       = errors.New("JWT 签发者不匹配")
+	ErrJwtAudience     = errors.New("JWT 受众不匹配")
+	ErrJwtMissingClaim = errors.New("JWT 缺少必需的声明")
+	ErrKeyType         = errors.New("密钥类型不匹配")
+)
+
+var jwtEncoding = base64.RawURLEncoding
+
+// JwtKey JWT 签名或验证使用的密钥
+// HS256 使用 Secret，其他算法使用 Private 签名、Public 验证
+type JwtKey struct {
+	ID      string
+	Alg     string
+	Secret  []byte
+	Private crypto.Signer
+	Public  crypto.PublicKey
+}
+
+// NewHmacJwtKey 创建 HS256 密钥
+func NewHmacJwtKey(kid string, secret []byte) *JwtKey {
+	return &JwtKey{ID: kid, Alg: JwtHS256, Secret: secret}
+}
+
+// NewJwtKey 由 PKCS8 私钥创建签名密钥，例如 RsaGenerate 返回的私钥，公钥从私钥导出
+func NewJwtKey(kid, alg string, privateKey []byte) (*JwtKey, error) {
+	priv, err := x509.ParsePKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := priv.(crypto.Signer)
+	if !ok {
+		return nil, ErrKeyType
+	}
+	if err := checkJwtKey(alg, signer.Public()); err != nil {
+		return nil, err
+	}
+	return &JwtKey{ID: kid, Alg: alg, Private: signer, Public: signer.Public()}, nil
+}
+
+// NewJwtPublicKey 由 PKIX 公钥创建只能验证的密钥
+func NewJwtPublicKey(kid, alg string, publicKey []byte) (*JwtKey, error) {
+	pub, err := x509.ParsePKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkJwtKey(alg, pub); err != nil {
+		return nil, err
+	}
+	return &JwtKey{ID: kid, Alg: alg, Public: pub}, nil
+}
+
+// checkJwtKey 检查公钥类型是否与算法一致
+func checkJwtKey(alg string, pub crypto.PublicKey) error {
+	switch alg {
+	case JwtRS256:
+		if _, ok := pub.(*rsa.PublicKey); ok {
+			return nil
+		}
+	case JwtES256:
+		if k, ok := pub.(*ecdsa.PublicKey); ok && k.Curve == elliptic.P256() {
+			return nil
+		}
+	case JwtEdDSA:
+		if _, ok := pub.(ed25519.PublicKey); ok {
+			return nil
+		}
+	default:
+		return ErrJwtAlg
+	}
+	return ErrKeyType
+}
+
+func (k *JwtKey) sign(input []byte) ([]byte, error) {
+	if k.Alg == JwtHS256 {
+		if len(k.Secret) == 0 {
+			return nil, ErrKeyType
+		}
+		mac := hmac.New(sha256.New, k.Secret)
+		mac.Write(input)
+		return mac.Sum(nil), nil
+	}
+	if k.Private == nil {
+		return nil, ErrNoPrivateKey
+	}
+	switch k.Alg {
+	case JwtRS256:
+		digest := sha256.Sum256(input)
+		return k.Private.Sign(rand.Reader, digest[:], crypto.SHA256)
+	case JwtES256:
+		priv, ok := k.Private.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, ErrKeyType
+		}
+		digest := sha256.Sum256(input)
+		r, s, err := ecdsa.Sign(rand.Reader, priv, digest[:])
+		if err != nil {
+			return nil, err
+		}
+		// JWS 使用定长的 r|s，而不是 ASN.1
+		sig := make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+		return sig, nil
+	case JwtEdDSA:
+		return k.Private.Sign(rand.Reader, input, crypto.Hash(0))
+	}
+	return nil, ErrJwtAlg
+}
+
+func (k *JwtKey) verify(input, sig []byte) error {
+	switch k.Alg {
+	case JwtHS256:
+		mac := hmac.New(sha256.New, k.Secret)
+		mac.Write(input)
+		if len(k.Secret) > 0 && hmac.Equal(sig, mac.Sum(nil)) {
+			return nil
+		}
+	case JwtRS256:
+		pub, ok := k.Public.(*rsa.PublicKey)
+		if !ok {
+			return ErrKeyType
+		}
+		digest := sha256.Sum256(input)
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig) == nil {
+			return nil
+		}
+	case JwtES256:
+		pub, ok := k.Public.(*ecdsa.PublicKey)
+		if !ok {
+			return ErrKeyType
+		}
+		digest := sha256.Sum256(input)
+		if len(sig) == 64 {
+			r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+			if ecdsa.Verify(pub, digest[:], r, s) {
+				return nil
+			}
+		}
+	case JwtEdDSA:
+		pub, ok := k.Public.(ed25519.PublicKey)
+		if !ok {
+			return ErrKeyType
+		}
+		if ed25519.Verify(pub, input, sig) {
+			return nil
+		}
+	default:
+		return ErrJwtAlg
+	}
+	return ErrJwtSignature
+}
+
+// Audience aud 声明，JSON 中可以是字符串或字符串数组
+type Audience []string
+
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*a = Audience{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+// RegisteredClaims JWT 标准声明，时间为 Unix 秒，0 表示不设置
+// 自定义声明嵌入 RegisteredClaims 即可用于 ParseJwt
+type RegisteredClaims struct {
+	Issuer    string   `json:"iss,omitempty"`
+	Subject   string   `json:"sub,omitempty"`
+	Audience  Audience `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	ID        string   `json:"jti,omitempty"`
+}
+
+// Registered 返回标准声明
+func (c *RegisteredClaims) Registered() *RegisteredClaims {
+	return c
+}
+
+// Claims 包含标准声明的 JWT 声明
+type Claims interface {
+	Registered() *RegisteredClaims
+}
+
+// JwtValidation 验证规则，Issuer、Audience 为空时不检查
+type JwtValidation struct {
+	Issuer   string
+	Audience string
+	// Leeway 允许的时钟偏差
+	Leeway time.Duration
+	// RequireExp 要求必须有 exp
+	RequireExp bool
+	// Now 当前时间，默认 time.Now
+	Now func() time.Time
+}
+
+// Validate 检查 exp、nbf、iat、iss 和 aud
+func (v JwtValidation) Validate(c *RegisteredClaims) error {
+	now := time.Now()
+	if v.Now != nil {
+		now = v.Now()
+	}
+	switch {
+	case c.ExpiresAt == 0 && v.RequireExp:
+		return ErrJwtMissingClaim
+	case c.ExpiresAt != 0 && !now.Before(time.Unix(c.ExpiresAt, 0).Add(v.Leeway)):
+		return ErrJwtExpired
+	case c.NotBefore != 0 && now.Add(v.Leeway).Before(time.Unix(c.NotBefore, 0)):
+		return ErrJwtNotYetValid
+	case c.IssuedAt != 0 && now.Add(v.Leeway).Before(time.Unix(c.IssuedAt, 0)):
+		return ErrJwtIssuedAt
+	case v.Issuer != "" && c.Issuer != v.Issuer:
+		return ErrJwtIssuer
+	case v.Audience != "" && !slices.Contains(c.Audience, v.Audience):
+		return ErrJwtAudience
+	}
+	return nil
+}
+
+// JwtKeySource 按 kid 查找验证密钥，kid 可能为空
+type JwtKeySource interface {
+	JwtKey(kid string) (*JwtKey, error)
+}
+
+// JwtKeySet 固定的密钥集合
+type JwtKeySet []*JwtKey
+
+// JwtKey 按 kid 查找密钥，kid 为空且只有一个密钥时返回该密钥
+func (s JwtKeySet) JwtKey(kid string) (*JwtKey, error) {
+	if kid == "" && len(s) == 1 {
+		return s[0], nil
+	}
+	for _, k := range s {
+		if k.ID == kid {
+			return k, nil
+		}
+	}
+	return nil, ErrKeyNotFound
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+	Kid string `json:"kid,omitempty"`
+}
+
+// SignJwt 签发 JWT，claims 可以是任意可 JSON 编码的值
+func SignJwt(key *JwtKey, claims any) (string, error) {
+	header, err := json.Marshal(jwtHeader{Alg: key.Alg, Typ: "JWT", Kid: key.ID})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	input := jwtEncoding.EncodeToString(header) + "." + jwtEncoding.EncodeToString(payload)
+	sig, err := key.sign([]byte(input))
+	if err != nil {
+		return "", err
+	}
+	return input + "." + jwtEncoding.EncodeToString(sig), nil
+}
+
+// ParseJwt 验证签名和声明并解析为 T，头部的 alg 必须与所选密钥的算法一致
+//
+//	claims, err := crypto.ParseJwt[MyClaims](token, keys, crypto.JwtValidation{Issuer: "auth"})
+func ParseJwt[T any, PT interface {
+	*T
+	Claims
+}](token string, keys JwtKeySource, v JwtValidation) (*T, error) {
+	payload, err := VerifyJwt(token, keys)
+	if err != nil {
+		return nil, err
+	}
+	claims := PT(new(T))
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, ErrJwtMalformed
+	}
+	if err := v.Validate(claims.Registered()); err != nil {
+		return nil, err
+	}
+	return (*T)(claims), nil
+}
+
+// VerifyJwt 只验证签名，返回 payload 的 JSON，不检查声明
+func VerifyJwt(token string, keys JwtKeySource) ([]byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrJwtMalformed
+	}
+	rawHeader, err := jwtEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrJwtMalformed
+	}
+	var header jwtHeader
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return nil, ErrJwtMalformed
+	}
+	sig, err := jwtEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrJwtMalformed
+	}
+	key, err := keys.JwtKey(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	// 以密钥的算法为准，防止算法混淆攻击（如 none 或用公钥做 HMAC）
+	if header.Alg != key.Alg {
+		return nil, ErrJwtAlg
+	}
+	if err := key.verify([]byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return nil, err
+	}
+	payload, err := jwtEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrJwtMalformed
+	}
+	return payload, nil
+}
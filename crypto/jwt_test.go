@@ -0,0 +1,243 @@
+package crypto_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Jsharkc/mygopkg/crypto"
+)
+
+type userClaims struct {
+	crypto.RegisteredClaims
+	Role string `json:"role"`
+}
+
+func jwtKeys(t *testing.T) []*crypto.JwtKey {
+	t.Helper()
+	rsaPriv, _, err := crypto.RsaGenerate(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecPriv, _ := x509.MarshalPKCS8PrivateKey(ecKey)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	edPriv, _ := x509.MarshalPKCS8PrivateKey(edKey)
+
+	keys := []*crypto.JwtKey{crypto.NewHmacJwtKey("hs", []byte("secret"))}
+	for _, k := range []struct {
+		kid, alg string
+		der      []byte
+	}{{"rs", crypto.JwtRS256, rsaPriv}, {"es", crypto.JwtES256, ecPriv}, {"ed", crypto.JwtEdDSA, edPriv}} {
+		key, err := crypto.NewJwtKey(k.kid, k.alg, k.der)
+		if err != nil {
+			t.Fatal(k.alg, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func TestJwt(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	v := crypto.JwtValidation{Issuer: "auth", Audience: "api", Leeway: time.Minute, Now: func() time.Time { return now }}
+	claims := userClaims{
+		RegisteredClaims: crypto.RegisteredClaims{
+			Issuer:    "auth",
+			Subject:   "42",
+			Audience:  crypto.Audience{"api"},
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(time.Hour).Unix(),
+		},
+		Role: "admin",
+	}
+
+	keys := crypto.JwtKeySet(jwtKeys(t))
+	for _, key := range keys {
+		token, err := crypto.SignJwt(key, claims)
+		if err != nil {
+			t.Fatal(key.Alg, err)
+		}
+		got, err := crypto.ParseJwt[userClaims](token, keys, v)
+		if err != nil || got.Role != "admin" || got.Subject != "42" {
+			t.Error(key.Alg, err)
+		}
+
+		parts := strings.Split(token, ".")
+		tampered := parts[0] + "." + parts[1] + "." + strings.Repeat("A", len(parts[2]))
+		if _, err := crypto.ParseJwt[userClaims](tampered, keys, v); !errors.Is(err, crypto.ErrJwtSignature) {
+			t.Error(key.Alg, "tampered", err)
+		}
+	}
+
+	// 用 HS256 密钥的 kid 签发 RS256 的头部必须被拒绝
+	forged := *keys[1]
+	forged.ID = "hs"
+	token, _ := crypto.SignJwt(&forged, claims)
+	if _, err := crypto.ParseJwt[userClaims](token, keys, v); !errors.Is(err, crypto.ErrJwtAlg) {
+		t.Error("alg confusion", err)
+	}
+	if _, err := crypto.ParseJwt[userClaims]("a.b", keys, v); !errors.Is(err, crypto.ErrJwtMalformed) {
+		t.Error("malformed", err)
+	}
+}
+
+func TestJwtValidation(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	v := crypto.JwtValidation{Issuer: "auth", Audience: "api", Leeway: 30 * time.Second, Now: func() time.Time { return now }}
+	valid := crypto.RegisteredClaims{Issuer: "auth", Audience: crypto.Audience{"web", "api"}}
+
+	cases := []struct {
+		name   string
+		modify func(c *crypto.RegisteredClaims)
+		err    error
+	}{
+		{"valid", func(c *crypto.RegisteredClaims) {}, nil},
+		{"expired", func(c *crypto.RegisteredClaims) { c.ExpiresAt = now.Add(-time.Minute).Unix() }, crypto.ErrJwtExpired},
+		{"expired within leeway", func(c *crypto.RegisteredClaims) { c.ExpiresAt = now.Add(-10 * time.Second).Unix() }, nil},
+		{"not before", func(c *crypto.RegisteredClaims) { c.NotBefore = now.Add(time.Minute).Unix() }, crypto.ErrJwtNotYetValid},
+		{"not before within leeway", func(c *crypto.RegisteredClaims) { c.NotBefore = now.Add(10 * time.Second).Unix() }, nil},
+		{"issued in future", func(c *crypto.RegisteredClaims) { c.IssuedAt = now.Add(time.Hour).Unix() }, crypto.ErrJwtIssuedAt},
+		{"issuer", func(c *crypto.RegisteredClaims) { c.Issuer = "other" }, crypto.ErrJwtIssuer},
+		{"audience", func(c *crypto.RegisteredClaims) { c.Audience = crypto.Audience{"web"} }, crypto.ErrJwtAudience},
+	}
+	for _, c := range cases {
+		claims := valid
+		c.modify(&claims)
+		if err := v.Validate(&claims); !errors.Is(err, c.err) {
+			t.Error(c.name, err)
+		}
+	}
+
+	v.RequireExp = true
+	if err := v.Validate(&valid); !errors.Is(err, crypto.ErrJwtMissingClaim) {
+		t.Error("require exp", err)
+	}
+}
+
+func TestJwks(t *testing.T) {
+	keys := crypto.JwtKeySet(jwtKeys(t))
+	handler, err := crypto.JwksHandler(keys, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := crypto.NewJwksClient(server.URL, time.Hour)
+	for _, key := range keys[1:] {
+		token, _ := crypto.SignJwt(key, crypto.RegisteredClaims{Subject: key.Alg})
+		got, err := crypto.ParseJwt[crypto.RegisteredClaims](token, client, crypto.JwtValidation{})
+		if err != nil || got.Subject != key.Alg {
+			t.Error(key.Alg, err)
+		}
+	}
+	if n := requests.Load(); n != 1 {
+		t.Error("jwks must be cached", n)
+	}
+
+	// HS256 密钥不能公开
+	token, _ := crypto.SignJwt(keys[0], crypto.RegisteredClaims{})
+	if _, err := crypto.ParseJwt[crypto.RegisteredClaims](token, client, crypto.JwtValidation{}); !errors.Is(err, crypto.ErrKeyNotFound) {
+		t.Error("hmac key must not be published", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Error("unknown kid refresh must be rate limited", n)
+	}
+}
+
+func TestJwksBackgroundRefresh(t *testing.T) {
+	keys := crypto.JwtKeySet(jwtKeys(t))
+	handler, err := crypto.JwksHandler(keys, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var requests atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 第一次之后的请求一直阻塞到 release 关闭
+		if requests.Add(1) > 1 {
+			<-release
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := crypto.NewJwksClient(server.URL, time.Millisecond)
+	kid := keys[1].ID
+	if _, err := client.JwtKey(kid); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	// 缓存过期后获取在后台进行，查找不会等待它，并发的过期查找只发起一次请求
+	done := make(chan error)
+	for i := 0; i < 4; i++ {
+		go func() {
+			_, err := client.JwtKey(kid)
+			done <- err
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Error(err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("lookup blocked by the refresh")
+		}
+	}
+	// 等待后台请求到达服务端
+	for deadline := time.Now().Add(5 * time.Second); requests.Load() < 2 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Error("one background refresh", n)
+	}
+}
+
+func TestJwkRsaSize(t *testing.T) {
+	// 1024 位的模数
+	weak := crypto.Jwk{Kty: "RSA", Kid: "weak", N: base64.RawURLEncoding.EncodeToString(bytes.Repeat([]byte{0xc5}, 128)), E: "AQAB"}
+	if _, err := weak.JwtKey(); err == nil {
+		t.Error("rsa key under 2048 bits must be rejected")
+	}
+	keys, err := crypto.ParseJwks([]byte(`{"keys":[{"kty":"RSA","kid":"weak","n":"` + weak.N + `","e":"AQAB"}]}`))
+	if err != nil || len(keys) != 0 {
+		t.Error("weak key must be skipped", keys, err)
+	}
+}
+
+func TestJwtKeyType(t *testing.T) {
+	priv, pub, _ := crypto.RsaGenerate(2048)
+	if _, err := crypto.NewJwtKey("k", crypto.JwtES256, priv); !errors.Is(err, crypto.ErrKeyType) {
+		t.Error("private", err)
+	}
+	if _, err := crypto.NewJwtPublicKey("k", crypto.JwtEdDSA, pub); !errors.Is(err, crypto.ErrKeyType) {
+		t.Error("public", err)
+	}
+	verifyOnly, err := crypto.NewJwtPublicKey("k", crypto.JwtRS256, pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := crypto.SignJwt(verifyOnly, nil); !errors.Is(err, crypto.ErrNoPrivateKey) {
+		t.Error("sign without private key", err)
+	}
+}
@@ -0,0 +1,419 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// WrapAlg 主密钥包装数据密钥的算法
+type WrapAlg byte
+
+const (
+	// WrapAesKw AES 密钥包装（RFC 3394），主密钥长度 16、24 或 32 字节
+	WrapAesKw WrapAlg = 1
+	// WrapRsaOaep RSA-OAEP，解包需要私钥
+	WrapRsaOaep WrapAlg = 2
+)
+
+// KeyringVersion 密文格式版本：
+// 版本(1字节) | 包装算法(1字节) | 密钥ID长度(1字节) | 密钥ID | 包装后数据密钥长度(2字节) | 包装后数据密钥 | 信封
+// 信封是 SealEnvelope 用数据密钥加密的结果，轮换主密钥时只需要重新包装数据密钥
+const KeyringVersion = 1
+
+const dataKeySize = 32
+
+var (
+	ErrNoPrimaryKey    = errors.New("未设置主密钥")
+	ErrKeyUnwrap       = errors.New("数据密钥解包失败")
+	ErrKeyringFormat   = errors.New("密文格式无效")
+	ErrUnknownWrapAlg  = errors.New("未知的密钥包装算法")
+	ErrInvalidWrapData = errors.New("待包装的密钥长度必须是 8 的倍数且不少于 16 字节")
+)
+
+func (alg WrapAlg) String() string {
+	switch alg {
+	case WrapAesKw:
+		return "AES-KW"
+	case WrapRsaOaep:
+		return "RSA-OAEP"
+	}
+	return fmt.Sprintf("WrapAlg(%d)", byte(alg))
+}
+
+// kwIV RFC 3394 默认初始值
+var kwIV = []byte{0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6}
+
+// AesKeyWrap AES 密钥包装（RFC 3394）
+func AesKeyWrap(kek, plain []byte) ([]byte, error) {
+	if len(plain) < 16 || len(plain)%8 != 0 {
+		return nil, ErrInvalidWrapData
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	n := len(plain) / 8
+	out := make([]byte, 8+len(plain))
+	copy(out, kwIV)
+	copy(out[8:], plain)
+
+	buf := make([]byte, 16)
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			copy(buf, out[:8])
+			copy(buf[8:], out[i*8:i*8+8])
+			block.Encrypt(buf, buf)
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(out[:8], binary.BigEndian.Uint64(buf[:8])^t)
+			copy(out[i*8:], buf[8:])
+		}
+	}
+	return out, nil
+}
+
+// AesKeyUnwrap 解开 AesKeyWrap 包装的密钥，完整性校验失败返回 ErrKeyUnwrap
+func AesKeyUnwrap(kek, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < 24 || len(wrapped)%8 != 0 {
+		return nil, ErrKeyUnwrap
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	n := len(wrapped)/8 - 1
+	a := make([]byte, 8)
+	copy(a, wrapped[:8])
+	out := make([]byte, len(wrapped)-8)
+	copy(out, wrapped[8:])
+
+	buf := make([]byte, 16)
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(buf[:8], binary.BigEndian.Uint64(a)^t)
+			copy(buf[8:], out[(i-1)*8:i*8])
+			block.Decrypt(buf, buf)
+			copy(a, buf[:8])
+			copy(out[(i-1)*8:], buf[8:])
+		}
+	}
+	if subtle.ConstantTimeCompare(a, kwIV) != 1 {
+		return nil, ErrKeyUnwrap
+	}
+	return out, nil
+}
+
+// MasterKey 主密钥，用于包装每个对象的数据密钥
+type MasterKey struct {
+	ID  string
+	Alg WrapAlg
+	// Key AES-KW 使用的对称密钥
+	Key []byte
+	// PublicKey、PrivateKey RSA-OAEP 使用的 PKIX 公钥和 PKCS8 私钥，只有公钥时只能加密
+	PublicKey  []byte
+	PrivateKey []byte
+}
+
+// NewAesMasterKey 创建 AES-KW 主密钥
+func NewAesMasterKey(id string, key []byte) (*MasterKey, error) {
+	if _, err := aes.NewCipher(key); err != nil {
+		return nil, err
+	}
+	return &MasterKey{ID: id, Alg: WrapAesKw, Key: key}, nil
+}
+
+// NewRsaMasterKey 创建 RSA-OAEP 主密钥，格式同 RsaGenerate，只给私钥时从中导出公钥
+func NewRsaMasterKey(id string, publicKey, privateKey []byte) (*MasterKey, error) {
+	if privateKey != nil {
+		priv, err := x509.ParsePKCS8PrivateKey(privateKey)
+		if err != nil {
+			return nil, err
+		}
+		rsaPriv, ok := priv.(*rsa.PrivateKey)
+		if !ok {
+			return nil, ErrNotRsaKey
+		}
+		if publicKey == nil {
+			if publicKey, err = x509.MarshalPKIXPublicKey(&rsaPriv.PublicKey); err != nil {
+				return nil, err
+			}
+		}
+	}
+	pub, err := x509.ParsePKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := pub.(*rsa.PublicKey); !ok {
+		return nil, ErrNotRsaKey
+	}
+	return &MasterKey{ID: id, Alg: WrapRsaOaep, PublicKey: publicKey, PrivateKey: privateKey}, nil
+}
+
+// Base64KeyPrefix 标记主密钥内容为 base64 编码
+const Base64KeyPrefix = "base64:"
+
+// ParseMasterKey 解析密钥文件或环境变量中的主密钥，以 Base64KeyPrefix 开头时按 base64 解码，
+// 否则为原始字节：16、24、32 字节为 AES 密钥，否则按 PKCS8 私钥或 PKIX 公钥解析为 RSA 密钥
+func ParseMasterKey(id string, data []byte) (*MasterKey, error) {
+	raw := data
+	if text, ok := strings.CutPrefix(strings.TrimSpace(string(data)), Base64KeyPrefix); ok {
+		decoded, err := base64.StdEncoding.DecodeString(text)
+		if err != nil {
+			return nil, fmt.Errorf("密钥 %s 的 base64 内容无效: %w", id, err)
+		}
+		raw = decoded
+	}
+	switch len(raw) {
+	case 16, 24, 32:
+		return NewAesMasterKey(id, raw)
+	}
+	if _, err := x509.ParsePKCS8PrivateKey(raw); err == nil {
+		return NewRsaMasterKey(id, nil, raw)
+	}
+	if _, err := x509.ParsePKIXPublicKey(raw); err == nil {
+		return NewRsaMasterKey(id, raw, nil)
+	}
+	return nil, fmt.Errorf("无法识别密钥 %s 的格式", id)
+}
+
+func (m *MasterKey) wrap(dataKey []byte) ([]byte, error) {
+	switch m.Alg {
+	case WrapAesKw:
+		return AesKeyWrap(m.Key, dataKey)
+	case WrapRsaOaep:
+		return RSAOAEPEncrypt(dataKey, m.PublicKey, []byte(m.ID))
+	}
+	return nil, ErrUnknownWrapAlg
+}
+
+func (m *MasterKey) unwrap(wrapped []byte) ([]byte, error) {
+	switch m.Alg {
+	case WrapAesKw:
+		return AesKeyUnwrap(m.Key, wrapped)
+	case WrapRsaOaep:
+		if m.PrivateKey == nil {
+			return nil, ErrNoPrivateKey
+		}
+		dataKey, err := RSAOAEPDecrypt(wrapped, m.PrivateKey, []byte(m.ID))
+		if err != nil {
+			return nil, ErrKeyUnwrap
+		}
+		return dataKey, nil
+	}
+	return nil, ErrUnknownWrapAlg
+}
+
+// Keyring 按密钥ID管理主密钥，使用主密钥（primary）加密，按密文头部的密钥ID解密，
+// 旧的主密钥保留在 Keyring 中即可继续解密历史数据
+type Keyring struct {
+	dataAlg AeadAlg
+
+	mu      sync.RWMutex
+	keys    map[string]*MasterKey
+	primary string
+}
+
+// NewKeyring 创建 Keyring，dataAlg 为数据密钥使用的认证加密算法
+func NewKeyring(dataAlg AeadAlg) *Keyring {
+	return &Keyring{dataAlg: dataAlg, keys: make(map[string]*MasterKey)}
+}
+
+// Add 添加主密钥，primary 为 true 时设为加密使用的主密钥
+func (k *Keyring) Add(key *MasterKey, primary bool) error {
+	if key.ID == "" || len(key.ID) > 255 {
+		return errors.New("密钥ID长度必须在 1 到 255 之间")
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys[key.ID] = key
+	if primary {
+		k.primary = key.ID
+	}
+	return nil
+}
+
+// AddFile 从文件读取主密钥，格式见 ParseMasterKey
+func (k *Keyring) AddFile(id, path string, primary bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	key, err := ParseMasterKey(id, data)
+	if err != nil {
+		return err
+	}
+	return k.Add(key, primary)
+}
+
+// AddEnv 从环境变量读取主密钥，格式见 ParseMasterKey
+func (k *Keyring) AddEnv(id, name string, primary bool) error {
+	data, ok := os.LookupEnv(name)
+	if !ok {
+		return fmt.Errorf("环境变量 %s 未设置", name)
+	}
+	key, err := ParseMasterKey(id, []byte(data))
+	if err != nil {
+		return err
+	}
+	return k.Add(key, primary)
+}
+
+// SetPrimary 切换主密钥，之后的加密和 Rewrap 使用该密钥
+func (k *Keyring) SetPrimary(id string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.keys[id]; !ok {
+		return ErrKeyNotFound
+	}
+	k.primary = id
+	return nil
+}
+
+// Primary 返回主密钥ID
+func (k *Keyring) Primary() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.primary
+}
+
+// Remove 移除主密钥，之后无法再解密用它包装的数据
+func (k *Keyring) Remove(id string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	delete(k.keys, id)
+	if k.primary == id {
+		k.primary = ""
+	}
+}
+
+func (k *Keyring) key(id string) (*MasterKey, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if id == "" {
+		return nil, ErrNoPrimaryKey
+	}
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return key, nil
+}
+
+// Encrypt 生成随机数据密钥加密 plain，并用主密钥包装数据密钥写入头部
+func (k *Keyring) Encrypt(plain, ad []byte) ([]byte, error) {
+	master, err := k.key(k.Primary())
+	if err != nil {
+		return nil, err
+	}
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+	envelope, err := SealEnvelope(k.dataAlg, dataKey, plain, ad)
+	if err != nil {
+		return nil, err
+	}
+	return sealKeyring(master, dataKey, envelope)
+}
+
+// Decrypt 按头部的密钥ID找到主密钥，解包数据密钥后解密
+func (k *Keyring) Decrypt(ciphertext, ad []byte) ([]byte, error) {
+	h, err := parseKeyring(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := k.unwrap(h)
+	if err != nil {
+		return nil, err
+	}
+	return OpenEnvelope(dataKey, h.envelope, ad)
+}
+
+// Rewrap 用当前主密钥重新包装数据密钥，数据本身不重新加密，用于主密钥轮换
+func (k *Keyring) Rewrap(ciphertext []byte) ([]byte, error) {
+	h, err := parseKeyring(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	master, err := k.key(k.Primary())
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := k.unwrap(h)
+	if err != nil {
+		return nil, err
+	}
+	return sealKeyring(master, dataKey, h.envelope)
+}
+
+// KeyID 返回密文使用的主密钥ID，可用于判断是否需要 Rewrap
+func KeyID(ciphertext []byte) (string, error) {
+	h, err := parseKeyring(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	return h.id, nil
+}
+
+func (k *Keyring) unwrap(h keyringHeader) ([]byte, error) {
+	master, err := k.key(h.id)
+	if err != nil {
+		return nil, err
+	}
+	if master.Alg != h.alg {
+		return nil, ErrUnknownWrapAlg
+	}
+	return master.unwrap(h.wrapped)
+}
+
+type keyringHeader struct {
+	alg      WrapAlg
+	id       string
+	wrapped  []byte
+	envelope []byte
+}
+
+func sealKeyring(master *MasterKey, dataKey, envelope []byte) ([]byte, error) {
+	wrapped, err := master.wrap(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, 3+len(master.ID)+2+len(wrapped)+len(envelope))
+	out = append(out, KeyringVersion, byte(master.Alg), byte(len(master.ID)))
+	out = append(out, master.ID...)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(wrapped)))
+	out = append(out, wrapped...)
+	return append(out, envelope...), nil
+}
+
+func parseKeyring(data []byte) (keyringHeader, error) {
+	var h keyringHeader
+	if len(data) < 3 || data[0] != KeyringVersion {
+		return h, ErrKeyringFormat
+	}
+	h.alg = WrapAlg(data[1])
+	idLen := int(data[2])
+	data = data[3:]
+	if len(data) < idLen+2 {
+		return h, ErrKeyringFormat
+	}
+	h.id = string(data[:idLen])
+	data = data[idLen:]
+	wrappedLen := int(binary.BigEndian.Uint16(data))
+	data = data[2:]
+	if len(data) < wrappedLen {
+		return h, ErrKeyringFormat
+	}
+	h.wrapped, h.envelope = data[:wrappedLen], data[wrappedLen:]
+	return h, nil
+}
@@ -0,0 +1,159 @@
+package crypto_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Jsharkc/mygopkg/crypto"
+)
+
+func TestAesKeyWrap(t *testing.T) {
+	// RFC 3394 4.1
+	kek, _ := hex.DecodeString("000102030405060708090A0B0C0D0E0F")
+	key, _ := hex.DecodeString("00112233445566778899AABBCCDDEEFF")
+	want := "1fa68b0a8112b447aef34bd8fb5a7b829d3e862371d2cfe5"
+
+	wrapped, err := crypto.AesKeyWrap(kek, key)
+	if err != nil || hex.EncodeToString(wrapped) != want {
+		t.Fatal(hex.EncodeToString(wrapped), err)
+	}
+	got, err := crypto.AesKeyUnwrap(kek, wrapped)
+	if err != nil || !bytes.Equal(got, key) {
+		t.Error("unwrap", err)
+	}
+	wrapped[3] ^= 1
+	if _, err := crypto.AesKeyUnwrap(kek, wrapped); !errors.Is(err, crypto.ErrKeyUnwrap) {
+		t.Error("tampered", err)
+	}
+}
+
+func TestKeyringRotate(t *testing.T) {
+	priv, pub, err := crypto.RsaGenerate(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldKey, _ := crypto.NewAesMasterKey("2023", bytes.Repeat([]byte{1}, 32))
+	newKey, err := crypto.NewRsaMasterKey("2024", nil, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ring := crypto.NewKeyring(crypto.AeadAesGcm)
+	if _, err := ring.Encrypt([]byte("x"), nil); !errors.Is(err, crypto.ErrNoPrimaryKey) {
+		t.Error("no primary", err)
+	}
+	_ = ring.Add(oldKey, true)
+	encrypted, err := ring.Encrypt([]byte("recording"), []byte("obj-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, _ := crypto.KeyID(encrypted); id != "2023" {
+		t.Error("key id", id)
+	}
+
+	// 轮换主密钥后旧数据仍然可以解密，Rewrap 之后不再依赖旧密钥
+	_ = ring.Add(newKey, true)
+	if plain, err := ring.Decrypt(encrypted, []byte("obj-1")); err != nil || string(plain) != "recording" {
+		t.Error("decrypt old", err)
+	}
+	rewrapped, err := ring.Rewrap(encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, _ := crypto.KeyID(rewrapped); id != "2024" {
+		t.Error("rewrapped key id", id)
+	}
+	ring.Remove("2023")
+	if _, err := ring.Decrypt(encrypted, []byte("obj-1")); !errors.Is(err, crypto.ErrKeyNotFound) {
+		t.Error("removed key", err)
+	}
+	if plain, err := ring.Decrypt(rewrapped, []byte("obj-1")); err != nil || string(plain) != "recording" {
+		t.Error("decrypt rewrapped", err)
+	}
+	if _, err := ring.Decrypt(rewrapped, []byte("obj-2")); err == nil {
+		t.Error("associated data must be authenticated")
+	}
+
+	// 只有公钥时可以加密但不能解密
+	encryptOnly := crypto.NewKeyring(crypto.AeadChaCha20Poly1305)
+	pubKey, _ := crypto.NewRsaMasterKey("2024", pub, nil)
+	_ = encryptOnly.Add(pubKey, true)
+	encrypted, err = encryptOnly.Encrypt([]byte("one way"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := encryptOnly.Decrypt(encrypted, nil); !errors.Is(err, crypto.ErrNoPrivateKey) {
+		t.Error("public only", err)
+	}
+	if plain, err := ring.Decrypt(encrypted, nil); err != nil || string(plain) != "one way" {
+		t.Error("decrypt with private", err)
+	}
+}
+
+func TestKeyringLoad(t *testing.T) {
+	priv, _, err := crypto.RsaGenerate(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "master.key")
+	if err := os.WriteFile(path, []byte(crypto.Base64KeyPrefix+base64.StdEncoding.EncodeToString(priv)+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_MASTER_KEY", crypto.Base64KeyPrefix+base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 16)))
+
+	ring := crypto.NewKeyring(crypto.AeadAesGcm)
+	if err := ring.AddFile("file", path, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := ring.AddEnv("env", "TEST_MASTER_KEY", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := ring.AddEnv("missing", "TEST_MASTER_KEY_MISSING", false); err == nil {
+		t.Error("missing env must fail")
+	}
+	if _, err := crypto.ParseMasterKey("bad", []byte("not a key")); err == nil {
+		t.Error("bad key must fail")
+	}
+	if _, err := crypto.ParseMasterKey("bad", []byte(crypto.Base64KeyPrefix+"!!")); err == nil {
+		t.Error("bad base64 must fail")
+	}
+
+	encrypted, _ := ring.Encrypt([]byte("a"), nil)
+	_ = ring.SetPrimary("file")
+	encrypted, _ = ring.Rewrap(encrypted)
+	if id, _ := crypto.KeyID(encrypted); id != "file" {
+		t.Error("key id", id)
+	}
+	if plain, err := ring.Decrypt(encrypted, nil); err != nil || string(plain) != "a" {
+		t.Error("decrypt", err)
+	}
+	if _, err := ring.Decrypt(encrypted[:4], nil); !errors.Is(err, crypto.ErrKeyringFormat) {
+		t.Error("short", err)
+	}
+}
+
+func TestParseMasterKeyRaw(t *testing.T) {
+	// 32 个可打印字符同时是合法的 base64，没有前缀时按原始字节作为 AES-256 密钥
+	ascii := "0123456789abcdefghijklmnopqrstuv"
+	key, err := crypto.ParseMasterKey("raw", []byte(ascii))
+	if err != nil || string(key.Key) != ascii {
+		t.Fatal("raw key", err)
+	}
+
+	// 24 个字符的 base64 解码为 16 字节
+	aes128 := bytes.Repeat([]byte{3}, 16)
+	encoded := base64.StdEncoding.EncodeToString(aes128)
+	key, err = crypto.ParseMasterKey("b64", []byte(crypto.Base64KeyPrefix+encoded+"\n"))
+	if err != nil || !bytes.Equal(key.Key, aes128) {
+		t.Fatal("base64 key", err)
+	}
+	key, err = crypto.ParseMasterKey("raw24", []byte(encoded))
+	if err != nil || len(key.Key) != 24 {
+		t.Fatal("unprefixed base64 is raw", err)
+	}
+}
@@ -0,0 +1,218 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 默认参数，与常见的身份验证器应用一致
+const (
+	OtpDefaultDigits = 6
+	OtpDefaultPeriod = 30 * time.Second
+	OtpSecretSize    = 20
+)
+
+var ErrOtpHash = errors.New("OTP 只支持 SHA1、SHA256 和 SHA512")
+
+var otpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateOtpSecret 生成 OtpSecretSize 字节的随机 OTP 密钥
+func GenerateOtpSecret() ([]byte, error) {
+	return RandomBytes(OtpSecretSize)
+}
+
+// EncodeOtpSecret 把密钥编码为身份验证器使用的 base32（无填充）
+func EncodeOtpSecret(secret []byte) string {
+	return otpEncoding.EncodeToString(secret)
+}
+
+// ParseOtpSecret 解析 base32 密钥，忽略大小写、空格和填充
+func ParseOtpSecret(s string) ([]byte, error) {
+	s = strings.ToUpper(strings.NewReplacer(" ", "", "-", "", "=", "").Replace(s))
+	return otpEncoding.DecodeString(s)
+}
+
+// otpParams HOTP 和 TOTP 共用的参数
+type otpParams struct {
+	digits int
+	hash   crypto.Hash
+}
+
+func newOtpParams(digits int, h crypto.Hash) (otpParams, error) {
+	if digits == 0 {
+		digits = OtpDefaultDigits
+	}
+	if h == 0 {
+		h = crypto.SHA1
+	}
+	if digits < 6 || digits > 10 {
+		return otpParams{}, errors.New("OTP 位数必须在 6 到 10 之间")
+	}
+	if h != crypto.SHA1 && h != crypto.SHA256 && h != crypto.SHA512 || !h.Available() {
+		return otpParams{}, ErrOtpHash
+	}
+	return otpParams{digits: digits, hash: h}, nil
+}
+
+// code RFC 4226 动态截断
+func (p otpParams) code(secret []byte, counter uint64) string {
+	mac := hmac.New(p.hash.New, secret)
+	_ = binary.Write(mac, binary.BigEndian, counter)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := uint64(binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff)
+	mod := uint64(1)
+	for i := 0; i < p.digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", p.digits, value%mod)
+}
+
+func otpAlgorithm(h crypto.Hash) string {
+	return strings.ReplaceAll(h.String(), "-", "")
+}
+
+func otpURI(typ, issuer, account string, secret []byte, p otpParams, extra url.Values) string {
+	label := account
+	if issuer != "" {
+		label = issuer + ":" + account
+	}
+	q := url.Values{}
+	q.Set("secret", EncodeOtpSecret(secret))
+	if issuer != "" {
+		q.Set("issuer", issuer)
+	}
+	q.Set("algorithm", otpAlgorithm(p.hash))
+	q.Set("digits", strconv.Itoa(p.digits))
+	for k, v := range extra {
+		q[k] = v
+	}
+	u := url.URL{Scheme: "otpauth", Host: typ, Path: "/" + label, RawQuery: q.Encode()}
+	return u.String()
+}
+
+// Hotp 基于计数器的一次性密码（RFC 4226）
+type Hotp struct {
+	Secret []byte
+	// Digits 位数，默认 6
+	Digits int
+	// Hash 默认 SHA1
+	Hash crypto.Hash
+	// LookAhead 验证时允许计数器向前跳过的次数
+	LookAhead int
+	Issuer    string
+	Account   string
+}
+
+// Code 计算计数器 counter 的密码
+func (h Hotp) Code(counter uint64) (string, error) {
+	p, err := newOtpParams(h.Digits, h.Hash)
+	if err != nil {
+		return "", err
+	}
+	return p.code(h.Secret, counter), nil
+}
+
+// Verify 在 [counter, counter+LookAhead] 内验证密码，成功时返回下一次应使用的计数器，
+// 调用方需要保存它以防重放
+func (h Hotp) Verify(code string, counter uint64) (uint64, bool) {
+	p, err := newOtpParams(h.Digits, h.Hash)
+	if err != nil || len(code) != p.digits {
+		return counter, false
+	}
+	matched, next := false, counter
+	// 检查完整个窗口，耗时与匹配位置无关
+	for i := 0; i <= h.LookAhead; i++ {
+		c := counter + uint64(i)
+		if subtle.ConstantTimeCompare([]byte(p.code(h.Secret, c)), []byte(code)) == 1 && !matched {
+			matched, next = true, c+1
+		}
+	}
+	return next, matched
+}
+
+// URI 生成 otpauth://hotp/ 链接，可生成二维码供身份验证器扫描
+func (h Hotp) URI(counter uint64) (string, error) {
+	p, err := newOtpParams(h.Digits, h.Hash)
+	if err != nil {
+		return "", err
+	}
+	return otpURI("hotp", h.Issuer, h.Account, h.Secret, p, url.Values{"counter": {strconv.FormatUint(counter, 10)}}), nil
+}
+
+// Totp 基于时间的一次性密码（RFC 6238）
+type Totp struct {
+	Secret []byte
+	// Digits 位数，默认 6
+	Digits int
+	// Hash 默认 SHA1
+	Hash crypto.Hash
+	// Period 时间步长，默认 30 秒
+	Period time.Duration
+	// Skew 验证时允许前后偏差的时间步数，用于容忍时钟漂移
+	Skew    int
+	Issuer  string
+	Account string
+}
+
+func (t Totp) period() time.Duration {
+	if t.Period < time.Second {
+		return OtpDefaultPeriod
+	}
+	return t.Period
+}
+
+// Step 返回 at 所在的时间步
+func (t Totp) Step(at time.Time) uint64 {
+	return uint64(at.Unix()) / uint64(t.period()/time.Second)
+}
+
+// Code 计算 at 时刻的密码
+func (t Totp) Code(at time.Time) (string, error) {
+	p, err := newOtpParams(t.Digits, t.Hash)
+	if err != nil {
+		return "", err
+	}
+	return p.code(t.Secret, t.Step(at)), nil
+}
+
+// Verify 在 at 前后 Skew 个时间步内验证密码，成功时返回匹配的时间步，
+// 调用方可以记录已使用的时间步，拒绝不大于它的时间步以防重放
+func (t Totp) Verify(code string, at time.Time) (uint64, bool) {
+	p, err := newOtpParams(t.Digits, t.Hash)
+	if err != nil || len(code) != p.digits {
+		return 0, false
+	}
+	step := t.Step(at)
+	var matchedStep uint64
+	matched := false
+	for i := -t.Skew; i <= t.Skew; i++ {
+		if i < 0 && uint64(-i) > step {
+			continue
+		}
+		s := step + uint64(i)
+		if subtle.ConstantTimeCompare([]byte(p.code(t.Secret, s)), []byte(code)) == 1 && !matched {
+			matched, matchedStep = true, s
+		}
+	}
+	return matchedStep, matched
+}
+
+// URI 生成 otpauth://totp/ 链接，可生成二维码供身份验证器扫描
+func (t Totp) URI() (string, error) {
+	p, err := newOtpParams(t.Digits, t.Hash)
+	if err != nil {
+		return "", err
+	}
+	extra := url.Values{"period": {strconv.Itoa(int(t.period() / time.Second))}}
+	return otpURI("totp", t.Issuer, t.Account, t.Secret, p, extra), nil
+}
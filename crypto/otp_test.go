@@ -0,0 +1,101 @@
+package crypto_test
+
+import (
+	stdcrypto "crypto"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Jsharkc/mygopkg/crypto"
+)
+
+func TestHotp(t *testing.T) {
+	// RFC 4226 附录 D
+	h := crypto.Hotp{Secret: []byte("12345678901234567890"), LookAhead: 3}
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for i, w := range want {
+		if code, err := h.Code(uint64(i)); err != nil || code != w {
+			t.Error(i, code, err)
+		}
+	}
+
+	if next, ok := h.Verify("969429", 1); !ok || next != 4 {
+		t.Error("look ahead", next, ok)
+	}
+	if next, ok := h.Verify("338314", 0); ok || next != 0 {
+		t.Error("beyond window", next, ok)
+	}
+	if _, ok := h.Verify("75522", 0); ok {
+		t.Error("short code")
+	}
+}
+
+func TestTotp(t *testing.T) {
+	// RFC 6238 附录 B
+	secrets := map[stdcrypto.Hash]string{
+		stdcrypto.SHA1:   "12345678901234567890",
+		stdcrypto.SHA256: "12345678901234567890123456789012",
+		stdcrypto.SHA512: "1234567890123456789012345678901234567890123456789012345678901234",
+	}
+	cases := []struct {
+		at   int64
+		want map[stdcrypto.Hash]string
+	}{
+		{59, map[stdcrypto.Hash]string{stdcrypto.SHA1: "94287082", stdcrypto.SHA256: "46119246", stdcrypto.SHA512: "90693936"}},
+		{1111111109, map[stdcrypto.Hash]string{stdcrypto.SHA1: "07081804", stdcrypto.SHA256: "68084774", stdcrypto.SHA512: "25091201"}},
+		{20000000000, map[stdcrypto.Hash]string{stdcrypto.SHA1: "65353130", stdcrypto.SHA256: "77737706", stdcrypto.SHA512: "47863826"}},
+	}
+	for _, c := range cases {
+		for h, want := range c.want {
+			totp := crypto.Totp{Secret: []byte(secrets[h]), Digits: 8, Hash: h}
+			if code, err := totp.Code(time.Unix(c.at, 0)); err != nil || code != want {
+				t.Error(c.at, h, code, err)
+			}
+		}
+	}
+
+	totp := crypto.Totp{Secret: []byte(secrets[stdcrypto.SHA1]), Skew: 1}
+	now := time.Unix(1700000000, 0)
+	code, _ := totp.Code(now.Add(-30 * time.Second))
+	if step, ok := totp.Verify(code, now); !ok || step != totp.Step(now)-1 {
+		t.Error("drift", step, ok)
+	}
+	code, _ = totp.Code(now.Add(-90 * time.Second))
+	if _, ok := totp.Verify(code, now); ok {
+		t.Error("outside window")
+	}
+	if _, err := (crypto.Totp{Secret: []byte("x"), Hash: stdcrypto.MD5}).Code(now); err == nil {
+		t.Error("md5 must be rejected")
+	}
+}
+
+func TestOtpURI(t *testing.T) {
+	secret, err := crypto.GenerateOtpSecret()
+	if err != nil || len(secret) != crypto.OtpSecretSize {
+		t.Fatal(err)
+	}
+	totp := crypto.Totp{Secret: secret, Issuer: "My App", Account: "alice@example.com"}
+	uri, err := totp.URI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/My App:alice@example.com" {
+		t.Fatal(uri, err)
+	}
+	q := u.Query()
+	if q.Get("issuer") != "My App" || q.Get("algorithm") != "SHA1" || q.Get("digits") != "6" || q.Get("period") != "30" {
+		t.Error(uri)
+	}
+	parsed, err := crypto.ParseOtpSecret(strings.ToLower(q.Get("secret")))
+	if err != nil || string(parsed) != string(secret) {
+		t.Error("secret", err)
+	}
+
+	hotp := crypto.Hotp{Secret: secret, Account: "bob", Hash: stdcrypto.SHA256}
+	uri, _ = hotp.URI(5)
+	if !strings.HasPrefix(uri, "otpauth://hotp/bob?") || !strings.Contains(uri, "counter=5") || !strings.Contains(uri, "algorithm=SHA256") {
+		t.Error(uri)
+	}
+}
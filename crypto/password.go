@@ -0,0 +1,297 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
+)
+
+// PasswordAlg 密码哈希算法
+type PasswordAlg string
+
+const (
+	PasswordArgon2id PasswordAlg = "argon2id"
+	PasswordBcrypt   PasswordAlg = "bcrypt"
+	PasswordScrypt   PasswordAlg = "scrypt"
+	// PasswordSha256 旧的 Sha256(password) 十六进制结果，只用于验证和迁移
+	PasswordSha256 PasswordAlg = "sha256"
+)
+
+// PasswordParams 密码哈希参数，只有 Alg 对应算法的参数生效
+type PasswordParams struct {
+	Alg PasswordAlg
+
+	// argon2id：内存(KiB)、迭代次数、并行度
+	Memory  uint32
+	Time    uint32
+	Threads uint8
+
+	// scrypt：N = 2^LogN
+	LogN uint8
+	R    int
+	P    int
+
+	// bcrypt
+	Cost int
+
+	// argon2id 和 scrypt 的盐和结果长度
+	SaltLen int
+	KeyLen  int
+}
+
+// DefaultPasswordParams HashPassword 使用的默认参数
+var DefaultPasswordParams = PasswordParams{
+	Alg:     PasswordArgon2id,
+	Memory:  64 * 1024,
+	Time:    3,
+	Threads: 4,
+	LogN:    15,
+	R:       8,
+	P:       1,
+	Cost:    bcrypt.DefaultCost,
+	SaltLen: 16,
+	KeyLen:  32,
+}
+
+var (
+	ErrPasswordHash   = errors.New("无法识别的密码哈希格式")
+	ErrPasswordParams = errors.New("密码哈希参数无效")
+)
+
+var phcEncoding = base64.RawStdEncoding
+
+// HashPassword 使用默认参数计算密码哈希，返回 PHC 格式字符串，例如
+// $argon2id$v=19$m=65536,t=3,p=4$<盐>$<哈希>
+func HashPassword(password string) (string, error) {
+	return HashPasswordWithParams(password, DefaultPasswordParams)
+}
+
+// HashPasswordWithParams 按指定算法和参数计算密码哈希
+// bcrypt 使用其自身的 $2a$ 格式，scrypt 为 $scrypt$ln=15,r=8,p=1$<盐>$<哈希>
+func HashPasswordWithParams(password string, p PasswordParams) (string, error) {
+	if err := p.validate(); err != nil {
+		return "", err
+	}
+	if p.Alg == PasswordBcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), p.Cost)
+		if err != nil {
+			return "", err
+		}
+		return string(hash), nil
+	}
+
+	salt := make([]byte, p.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	h := passwordHash{params: p, salt: salt}
+	key, err := h.derive(password, p.KeyLen)
+	if err != nil {
+		return "", err
+	}
+	h.key = key
+	return h.String(), nil
+}
+
+// VerifyPassword 验证密码，使用常量时间比较
+// 密码不匹配时返回 false 和 nil，哈希格式错误时返回错误
+func VerifyPassword(password, encoded string) (bool, error) {
+	h, err := parsePasswordHash(encoded)
+	if err != nil {
+		return false, err
+	}
+	switch h.params.Alg {
+	case PasswordBcrypt:
+		err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return err == nil, err
+	case PasswordSha256:
+		return subtle.ConstantTimeCompare([]byte(Sha256(password)), []byte(strings.ToLower(encoded))) == 1, nil
+	}
+	key, err := h.derive(password, len(h.key))
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(key, h.key) == 1, nil
+}
+
+// NeedsRehash 判断哈希是否需要按 p 重新计算，算法不同或参数低于 p 时返回 true，
+// 可以在登录验证成功后调用，用新哈希替换旧哈希
+func NeedsRehash(encoded string, p PasswordParams) bool {
+	h, err := parsePasswordHash(encoded)
+	if err != nil || h.params.Alg != p.Alg {
+		return true
+	}
+	cur := h.params
+	switch p.Alg {
+	case PasswordArgon2id:
+		return cur.Memory < p.Memory || cur.Time < p.Time || cur.Threads < p.Threads ||
+			len(h.salt) < p.SaltLen || len(h.key) < p.KeyLen
+	case PasswordScrypt:
+		return cur.LogN < p.LogN || cur.R < p.R || cur.P < p.P ||
+			len(h.salt) < p.SaltLen || len(h.key) < p.KeyLen
+	case PasswordBcrypt:
+		return cur.Cost < p.Cost
+	}
+	return true
+}
+
+// validate 检查用于计算新哈希的参数，避免零值参数导致 panic 或生成弱哈希
+func (p PasswordParams) validate() error {
+	switch p.Alg {
+	case PasswordBcrypt:
+		if p.Cost < bcrypt.MinCost || p.Cost > bcrypt.MaxCost {
+			return fmt.Errorf("%w: bcrypt cost 必须在 %d 到 %d 之间", ErrPasswordParams, bcrypt.MinCost, bcrypt.MaxCost)
+		}
+		return nil
+	case PasswordArgon2id:
+		if p.Time < 1 || p.Threads < 1 {
+			return fmt.Errorf("%w: argon2id 的 Time 和 Threads 至少为 1", ErrPasswordParams)
+		}
+		// RFC 9106 要求内存至少为 8*并行度 KiB
+		if p.Memory < 8*uint32(p.Threads) {
+			return fmt.Errorf("%w: argon2id 的 Memory 至少为 8*Threads KiB", ErrPasswordParams)
+		}
+	case PasswordScrypt:
+		// 与解析哈希时的限制一致，1<<LogN 不能溢出
+		if p.LogN < 1 || p.LogN > 62 {
+			return fmt.Errorf("%w: scrypt 的 LogN 必须在 1 到 62 之间", ErrPasswordParams)
+		}
+		if p.R < 1 || p.P < 1 || uint64(p.R)*uint64(p.P) >= 1<<30 {
+			return fmt.Errorf("%w: scrypt 的 R 和 P 至少为 1，且 R*P 小于 2^30", ErrPasswordParams)
+		}
+	default:
+		return fmt.Errorf("不支持的密码哈希算法 %s", p.Alg)
+	}
+	if p.SaltLen < 8 {
+		return fmt.Errorf("%w: 盐至少为 8 字节", ErrPasswordParams)
+	}
+	if p.KeyLen < 16 {
+		return fmt.Errorf("%w: 哈希结果至少为 16 字节", ErrPasswordParams)
+	}
+	return nil
+}
+
+type passwordHash struct {
+	params PasswordParams
+	salt   []byte
+	key    []byte
+}
+
+func (h passwordHash) derive(password string, keyLen int) ([]byte, error) {
+	p := h.params
+	switch p.Alg {
+	case PasswordArgon2id:
+		return argon2.IDKey([]byte(password), h.salt, p.Time, p.Memory, p.Threads, uint32(keyLen)), nil
+	case PasswordScrypt:
+		return scrypt.Key([]byte(password), h.salt, 1<<p.LogN, p.R, p.P, keyLen)
+	}
+	return nil, ErrPasswordHash
+}
+
+func (h passwordHash) String() string {
+	p := h.params
+	var params string
+	switch p.Alg {
+	case PasswordArgon2id:
+		params = fmt.Sprintf("v=%d$m=%d,t=%d,p=%d", argon2.Version, p.Memory, p.Time, p.Threads)
+	case PasswordScrypt:
+		params = fmt.Sprintf("ln=%d,r=%d,p=%d", p.LogN, p.R, p.P)
+	}
+	return "$" + string(p.Alg) + "$" + params + "$" + phcEncoding.EncodeToString(h.salt) + "$" + phcEncoding.EncodeToString(h.key)
+}
+
+func parsePasswordHash(encoded string) (passwordHash, error) {
+	var h passwordHash
+	if len(encoded) == 64 && !strings.HasPrefix(encoded, "$") {
+		if _, err := hex.DecodeString(encoded); err != nil {
+			return h, ErrPasswordHash
+		}
+		h.params.Alg = PasswordSha256
+		return h, nil
+	}
+	if strings.HasPrefix(encoded, "$2") {
+		cost, err := bcrypt.Cost([]byte(encoded))
+		if err != nil {
+			return h, ErrPasswordHash
+		}
+		h.params = PasswordParams{Alg: PasswordBcrypt, Cost: cost}
+		return h, nil
+	}
+
+	parts := strings.Split(encoded, "$")
+	if len(parts) < 5 || parts[0] != "" {
+		return h, ErrPasswordHash
+	}
+	h.params.Alg = PasswordAlg(parts[1])
+	var values map[string]int
+	var err error
+	switch h.params.Alg {
+	case PasswordArgon2id:
+		if len(parts) != 6 || parts[2] != "v="+strconv.Itoa(argon2.Version) {
+			return h, ErrPasswordHash
+		}
+		parts = append(parts[:2], parts[3:]...)
+		if values, err = phcParams(parts[2], "m", "t", "p"); err != nil {
+			return h, err
+		}
+		if values["t"] < 1 || values["p"] < 1 || values["p"] > 255 {
+			return h, ErrPasswordHash
+		}
+		h.params.Memory, h.params.Time, h.params.Threads = uint32(values["m"]), uint32(values["t"]), uint8(values["p"])
+	case PasswordScrypt:
+		if len(parts) != 5 {
+			return h, ErrPasswordHash
+		}
+		if values, err = phcParams(parts[2], "ln", "r", "p"); err != nil {
+			return h, err
+		}
+		if values["ln"] < 1 || values["ln"] > 62 {
+			return h, ErrPasswordHash
+		}
+		h.params.LogN, h.params.R, h.params.P = uint8(values["ln"]), values["r"], values["p"]
+	default:
+		return h, ErrPasswordHash
+	}
+
+	if h.salt, err = phcEncoding.DecodeString(parts[3]); err != nil {
+		return h, ErrPasswordHash
+	}
+	if h.key, err = phcEncoding.DecodeString(parts[4]); err != nil || len(h.key) == 0 {
+		return h, ErrPasswordHash
+	}
+	return h, nil
+}
+
+// phcParams 解析 a=1,b=2 形式的参数，names 中的参数都必须存在
+func phcParams(s string, names ...string) (map[string]int, error) {
+	values := make(map[string]int, len(names))
+	for _, kv := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, ErrPasswordHash
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, ErrPasswordHash
+		}
+		values[k] = n
+	}
+	for _, name := range names {
+		if _, ok := values[name]; !ok {
+			return nil, ErrPasswordHash
+		}
+	}
+	return values, nil
+}
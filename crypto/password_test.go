@@ -0,0 +1,114 @@
+package crypto_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Jsharkc/mygopkg/crypto"
+)
+
+// 测试使用较低的参数，避免拖慢测试
+var fastParams = crypto.PasswordParams{
+	Alg:     crypto.PasswordArgon2id,
+	Memory:  1024,
+	Time:    1,
+	Threads: 1,
+	LogN:    10,
+	R:       8,
+	P:       1,
+	Cost:    4,
+	SaltLen: 16,
+	KeyLen:  32,
+}
+
+func TestPassword(t *testing.T) {
+	for _, alg := range []crypto.PasswordAlg{crypto.PasswordArgon2id, crypto.PasswordScrypt, crypto.PasswordBcrypt} {
+		p := fastParams
+		p.Alg = alg
+		encoded, err := crypto.HashPasswordWithParams("s3cret", p)
+		if err != nil {
+			t.Fatal(alg, err)
+		}
+		if alg != crypto.PasswordBcrypt && !strings.HasPrefix(encoded, "$"+string(alg)+"$") {
+			t.Error(alg, "format", encoded)
+		}
+		again, _ := crypto.HashPasswordWithParams("s3cret", p)
+		if again == encoded {
+			t.Error(alg, "salt must be random")
+		}
+		if ok, err := crypto.VerifyPassword("s3cret", encoded); !ok || err != nil {
+			t.Error(alg, "verify", err)
+		}
+		if ok, err := crypto.VerifyPassword("wrong", encoded); ok || err != nil {
+			t.Error(alg, "wrong password", err)
+		}
+		if crypto.NeedsRehash(encoded, p) {
+			t.Error(alg, "same params must not need rehash")
+		}
+	}
+
+	encoded, _ := crypto.HashPasswordWithParams("s3cret", fastParams)
+	if !strings.HasPrefix(encoded, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Error("phc", encoded)
+	}
+	stronger := fastParams
+	stronger.Time = 2
+	if !crypto.NeedsRehash(encoded, stronger) {
+		t.Error("stronger params must need rehash")
+	}
+	bcryptParams := fastParams
+	bcryptParams.Alg = crypto.PasswordBcrypt
+	if !crypto.NeedsRehash(encoded, bcryptParams) {
+		t.Error("other algorithm must need rehash")
+	}
+}
+
+func TestPasswordParams(t *testing.T) {
+	// 零值参数返回错误而不是 panic
+	if _, err := crypto.HashPasswordWithParams("s3cret", crypto.PasswordParams{Alg: crypto.PasswordArgon2id}); !errors.Is(err, crypto.ErrPasswordParams) {
+		t.Error("zero argon2id params", err)
+	}
+	invalid := []func(p *crypto.PasswordParams){
+		func(p *crypto.PasswordParams) { p.Time = 0 },
+		func(p *crypto.PasswordParams) { p.Threads = 0 },
+		func(p *crypto.PasswordParams) { p.Memory = 0 },
+		func(p *crypto.PasswordParams) { p.SaltLen = 4 },
+		func(p *crypto.PasswordParams) { p.KeyLen = 8 },
+		func(p *crypto.PasswordParams) { p.Alg, p.LogN = crypto.PasswordScrypt, 0 },
+		func(p *crypto.PasswordParams) { p.Alg, p.LogN = crypto.PasswordScrypt, 63 },
+		func(p *crypto.PasswordParams) { p.Alg, p.R = crypto.PasswordScrypt, 0 },
+		func(p *crypto.PasswordParams) { p.Alg, p.R, p.P = crypto.PasswordScrypt, 1<<15, 1<<15 },
+		func(p *crypto.PasswordParams) { p.Alg, p.Cost = crypto.PasswordBcrypt, 2 },
+		func(p *crypto.PasswordParams) { p.Alg, p.Cost = crypto.PasswordBcrypt, 32 },
+	}
+	for i, change := range invalid {
+		p := fastParams
+		change(&p)
+		if _, err := crypto.HashPasswordWithParams("s3cret", p); !errors.Is(err, crypto.ErrPasswordParams) {
+			t.Error(i, err)
+		}
+	}
+	if _, err := crypto.HashPasswordWithParams("s3cret", crypto.PasswordParams{Alg: "md5"}); err == nil {
+		t.Error("unknown algorithm must fail")
+	}
+}
+
+func TestPasswordLegacy(t *testing.T) {
+	legacy := crypto.Sha256("123456")
+	if ok, err := crypto.VerifyPassword("123456", legacy); !ok || err != nil {
+		t.Error("legacy", err)
+	}
+	if ok, _ := crypto.VerifyPassword("1234567", legacy); ok {
+		t.Error("legacy wrong password")
+	}
+	if !crypto.NeedsRehash(legacy, crypto.DefaultPasswordParams) {
+		t.Error("legacy must need rehash")
+	}
+
+	for _, bad := range []string{"", "plain", "$argon2id$v=19$m=1,t=0,p=1$c2FsdA$a2V5", "$md5$x$y$z", "$scrypt$ln=10$c2FsdA$a2V5"} {
+		if _, err := crypto.VerifyPassword("x", bad); err == nil {
+			t.Error("malformed", bad)
+		}
+	}
+}
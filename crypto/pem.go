@@ -0,0 +1,244 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// PEM 块类型
+const (
+	PemPrivateKey          = "PRIVATE KEY"
+	PemEncryptedPrivateKey = "ENCRYPTED PRIVATE KEY"
+	PemRsaPrivateKey       = "RSA PRIVATE KEY"
+	PemEcPrivateKey        = "EC PRIVATE KEY"
+	PemPublicKey           = "PUBLIC KEY"
+	PemRsaPublicKey        = "RSA PUBLIC KEY"
+	PemCertificate         = "CERTIFICATE"
+)
+
+var (
+	ErrNotPem       = errors.New("不是 PEM 格式")
+	ErrKeyEncrypted = errors.New("私钥已加密，需要口令")
+	ErrUnknownKey   = errors.New("无法识别的密钥格式")
+	ErrNotRsaKey    = errors.New("不是 RSA 密钥")
+	ErrNotEcdsaKey  = errors.New("不是 ECDSA 密钥")
+	ErrNotEd25519   = errors.New("不是 Ed25519 密钥")
+	ErrNoPrivateKey = errors.New("缺少私钥")
+)
+
+// PemEncode 把 DER 编码为 PEM
+func PemEncode(typ string, der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der})
+}
+
+// PemDecode 解码第一个 PEM 块，返回块类型和 DER
+func PemDecode(data []byte) (string, []byte, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return "", nil, ErrNotPem
+	}
+	return block.Type, block.Bytes, nil
+}
+
+// ParsePrivateKey 解析私钥，支持 PEM 或 DER 编码的 PKCS8、PKCS1 和 SEC1，
+// 加密的私钥返回 ErrKeyEncrypted，请使用 ParsePrivateKeyWithPassphrase
+func ParsePrivateKey(data []byte) (crypto.Signer, error) {
+	return ParsePrivateKeyWithPassphrase(data, nil)
+}
+
+// ParsePrivateKeyWithPassphrase 解析私钥，加密的 PKCS8 私钥使用 passphrase 解密
+func ParsePrivateKeyWithPassphrase(data, passphrase []byte) (crypto.Signer, error) {
+	typ, der, err := PemDecode(data)
+	if err != nil {
+		// 不是 PEM 时按 DER 依次尝试
+		typ, der = "", data
+	}
+
+	switch typ {
+	case PemEncryptedPrivateKey:
+		if passphrase == nil {
+			return nil, ErrKeyEncrypted
+		}
+		if der, err = DecryptPKCS8(der, passphrase); err != nil {
+			return nil, err
+		}
+		return parsePKCS8Signer(der)
+	case PemPrivateKey:
+		return parsePKCS8Signer(der)
+	case PemRsaPrivateKey:
+		return x509.ParsePKCS1PrivateKey(der)
+	case PemEcPrivateKey:
+		return x509.ParseECPrivateKey(der)
+	case "":
+		if key, err := parsePKCS8Signer(der); err == nil {
+			return key, nil
+		}
+		if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+			return key, nil
+		}
+		if key, err := x509.ParseECPrivateKey(der); err == nil {
+			return key, nil
+		}
+		if passphrase != nil {
+			if plain, err := DecryptPKCS8(der, passphrase); err == nil {
+				return parsePKCS8Signer(plain)
+			}
+		}
+		return nil, ErrUnknownKey
+	}
+	return nil, fmt.Errorf("不支持的 PEM 类型 %s", typ)
+}
+
+func parsePKCS8Signer(der []byte) (crypto.Signer, error) {
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return signer, nil
+}
+
+// ParsePublicKey 解析公钥，支持 PEM 或 DER 编码的 PKIX、PKCS1 和证书
+func ParsePublicKey(data []byte) (crypto.PublicKey, error) {
+	typ, der, err := PemDecode(data)
+	if err != nil {
+		typ, der = "", data
+	}
+
+	switch typ {
+	case PemPublicKey:
+		return x509.ParsePKIXPublicKey(der)
+	case PemRsaPublicKey:
+		return x509.ParsePKCS1PublicKey(der)
+	case PemCertificate:
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	case "":
+		if key, err := x509.ParsePKIXPublicKey(der); err == nil {
+			return key, nil
+		}
+		if key, err := x509.ParsePKCS1PublicKey(der); err == nil {
+			return key, nil
+		}
+		if cert, err := x509.ParseCertificate(der); err == nil {
+			return cert.PublicKey, nil
+		}
+		return nil, ErrUnknownKey
+	}
+	return nil, fmt.Errorf("不支持的 PEM 类型 %s", typ)
+}
+
+// MarshalPrivateKeyPem 把私钥编码为 PKCS8 PEM，passphrase 不为空时加密
+func MarshalPrivateKeyPem(key crypto.Signer, passphrase []byte) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(passphrase) == 0 {
+		return PemEncode(PemPrivateKey, der), nil
+	}
+	encrypted, err := EncryptPKCS8(der, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return PemEncode(PemEncryptedPrivateKey, encrypted), nil
+}
+
+// MarshalPublicKeyPem 把公钥编码为 PKIX PEM
+func MarshalPublicKeyPem(key crypto.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return PemEncode(PemPublicKey, der), nil
+}
+
+// parseRsaPrivateKey 解析 PKCS8 私钥并确认是 RSA 密钥
+func parseRsaPrivateKey(der []byte) (*rsa.PrivateKey, error) {
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrNotRsaKey, key)
+	}
+	return rsaKey, nil
+}
+
+// parseRsaPublicKey 解析 PKIX 公钥并确认是 RSA 密钥
+func parseRsaPublicKey(der []byte) (*rsa.PublicKey, error) {
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrNotRsaKey, key)
+	}
+	return rsaKey, nil
+}
+
+// parseEcdsaPrivateKey 解析 PKCS8 私钥并确认是 ECDSA 密钥
+func parseEcdsaPrivateKey(der []byte) (*ecdsa.PrivateKey, error) {
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	ecKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrNotEcdsaKey, key)
+	}
+	return ecKey, nil
+}
+
+// parseEcdsaPublicKey 解析 PKIX 公钥并确认是 ECDSA 密钥
+func parseEcdsaPublicKey(der []byte) (*ecdsa.PublicKey, error) {
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+	ecKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrNotEcdsaKey, key)
+	}
+	return ecKey, nil
+}
+
+// parseEd25519PrivateKey 解析 PKCS8 私钥并确认是 Ed25519 密钥
+func parseEd25519PrivateKey(der []byte) (ed25519.PrivateKey, error) {
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrNotEd25519, key)
+	}
+	return edKey, nil
+}
+
+// parseEd25519PublicKey 解析 PKIX 公钥并确认是 Ed25519 密钥
+func parseEd25519PublicKey(der []byte) (ed25519.PublicKey, error) {
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrNotEd25519, key)
+	}
+	return edKey, nil
+}
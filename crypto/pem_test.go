@@ -0,0 +1,146 @@
+package crypto_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"testing"
+
+	"github.com/Jsharkc/mygopkg/crypto"
+)
+
+func TestPemKeys(t *testing.T) {
+	rsaPriv, rsaPub, err := crypto.RsaGenerate(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecPriv, ecPub, err := crypto.EcdsaGenerate(elliptic.P384())
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPriv, edPub, err := crypto.Ed25519Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, pair := range [][2][]byte{{rsaPriv, rsaPub}, {ecPriv, ecPub}, {edPriv, edPub}} {
+		// DER 和 PEM 都能解析
+		for _, data := range [][]byte{pair[0], crypto.PemEncode(crypto.PemPrivateKey, pair[0])} {
+			if _, err := crypto.ParsePrivateKey(data); err != nil {
+				t.Error("private", err)
+			}
+		}
+		for _, data := range [][]byte{pair[1], crypto.PemEncode(crypto.PemPublicKey, pair[1])} {
+			if _, err := crypto.ParsePublicKey(data); err != nil {
+				t.Error("public", err)
+			}
+		}
+
+		key, _ := crypto.ParsePrivateKey(pair[0])
+		encrypted, err := crypto.MarshalPrivateKeyPem(key, []byte("pass"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if typ, _, _ := crypto.PemDecode(encrypted); typ != crypto.PemEncryptedPrivateKey {
+			t.Error("type", typ)
+		}
+		if _, err := crypto.ParsePrivateKey(encrypted); !errors.Is(err, crypto.ErrKeyEncrypted) {
+			t.Error("needs passphrase", err)
+		}
+		if _, err := crypto.ParsePrivateKeyWithPassphrase(encrypted, []byte("wrong")); err == nil {
+			t.Error("wrong passphrase must fail")
+		}
+		decrypted, err := crypto.ParsePrivateKeyWithPassphrase(encrypted, []byte("pass"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		der, _ := x509.MarshalPKCS8PrivateKey(decrypted)
+		if !bytes.Equal(der, pair[0]) {
+			t.Error("decrypted key differs")
+		}
+	}
+
+	// PKCS1 和 SEC1
+	rsaKey, _ := crypto.ParsePrivateKey(rsaPriv)
+	pkcs1 := crypto.PemEncode(crypto.PemRsaPrivateKey, x509.MarshalPKCS1PrivateKey(rsaKey.(*rsa.PrivateKey)))
+	if key, err := crypto.ParsePrivateKey(pkcs1); err != nil || !key.(*rsa.PrivateKey).Equal(rsaKey) {
+		t.Error("pkcs1", err)
+	}
+	pkcs1Pub := crypto.PemEncode(crypto.PemRsaPublicKey, x509.MarshalPKCS1PublicKey(rsaKey.Public().(*rsa.PublicKey)))
+	if _, err := crypto.ParsePublicKey(pkcs1Pub); err != nil {
+		t.Error("pkcs1 public", err)
+	}
+	ecKey, _ := crypto.ParsePrivateKey(ecPriv)
+	sec1, _ := x509.MarshalECPrivateKey(ecKey.(*ecdsa.PrivateKey))
+	if _, err := crypto.ParsePrivateKey(sec1); err != nil {
+		t.Error("sec1", err)
+	}
+	if _, err := crypto.ParsePrivateKey([]byte("garbage")); !errors.Is(err, crypto.ErrUnknownKey) {
+		t.Error("garbage", err)
+	}
+	pem, _ := crypto.MarshalPublicKeyPem(ecKey.Public())
+	if key, err := crypto.ParsePublicKey(pem); err != nil || !key.(*ecdsa.PublicKey).Equal(ecKey.Public()) {
+		t.Error("public pem", err)
+	}
+}
+
+func TestEcdsaEd25519(t *testing.T) {
+	msg := []byte("message")
+	for _, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P384()} {
+		priv, pub, err := crypto.EcdsaGenerate(curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := crypto.EcdsaSign(msg, priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := crypto.EcdsaVerify(msg, pub, sig); err != nil {
+			t.Error(curve.Params().Name, err)
+		}
+		if err := crypto.EcdsaVerify([]byte("other"), pub, sig); !errors.Is(err, crypto.ErrSignature) {
+			t.Error(curve.Params().Name, "other message", err)
+		}
+	}
+	if _, _, err := crypto.EcdsaGenerate(elliptic.P224()); !errors.Is(err, crypto.ErrUnsupportedCurve) {
+		t.Error("p224", err)
+	}
+
+	priv, pub, _ := crypto.Ed25519Generate()
+	sig, err := crypto.Ed25519Sign(msg, priv)
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		t.Fatal(err)
+	}
+	if err := crypto.Ed25519Verify(msg, pub, sig); err != nil {
+		t.Error(err)
+	}
+	sig[0] ^= 1
+	if err := crypto.Ed25519Verify(msg, pub, sig); !errors.Is(err, crypto.ErrSignature) {
+		t.Error("tampered", err)
+	}
+}
+
+func TestKeyTypeMismatch(t *testing.T) {
+	edPriv, edPub, _ := crypto.Ed25519Generate()
+	ecPriv, ecPub, _ := crypto.EcdsaGenerate(elliptic.P256())
+
+	if _, err := crypto.RSASign([]byte("x"), ecPriv); !errors.Is(err, crypto.ErrNotRsaKey) {
+		t.Error("rsa sign", err)
+	}
+	if err := crypto.RSAVerify([]byte("x"), edPub, nil); !errors.Is(err, crypto.ErrNotRsaKey) {
+		t.Error("rsa verify", err)
+	}
+	if _, err := crypto.RSAOAEPEncrypt([]byte("x"), ecPub, nil); !errors.Is(err, crypto.ErrNotRsaKey) {
+		t.Error("rsa encrypt", err)
+	}
+	if _, err := crypto.EcdsaSign([]byte("x"), edPriv); !errors.Is(err, crypto.ErrNotEcdsaKey) {
+		t.Error("ecdsa sign", err)
+	}
+	if _, err := crypto.Ed25519Sign([]byte("x"), ecPriv); !errors.Is(err, crypto.ErrNotEd25519) {
+		t.Error("ed25519 sign", err)
+	}
+}
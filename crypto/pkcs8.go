@@ -0,0 +1,161 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"hash"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// 加密 PKCS8（RFC 5958、RFC 8018 PBES2）使用的 OID
+var (
+	oidPBES2      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHmacSha1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHmacSha256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidAes128Cbc  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAes192Cbc  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAes256Cbc  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+)
+
+// PKCS8Iterations EncryptPKCS8 使用的 PBKDF2 迭代次数
+var PKCS8Iterations = 100000
+
+var (
+	ErrPassphrase      = errors.New("口令错误或私钥已损坏")
+	ErrUnsupportedPBES = errors.New("不支持的私钥加密算法，只支持 PBES2 + PBKDF2 + AES-CBC")
+)
+
+type encryptedPrivateKeyInfo struct {
+	Algo          pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt       []byte
+	Iterations int
+	KeyLength  int                      `asn1:"optional"`
+	Prf        pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// EncryptPKCS8 用口令加密 PKCS8 私钥，使用 PBKDF2-HMAC-SHA256 和 AES-256-CBC，
+// 结果与 openssl pkcs8 -topk8 -v2 aes-256-cbc 兼容
+func EncryptPKCS8(der, passphrase []byte) ([]byte, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	iv, err := AesIvGenerate()
+	if err != nil {
+		return nil, err
+	}
+	key := pbkdf2.Key(passphrase, salt, PKCS8Iterations, 32, sha256.New)
+	encrypted, err := AesByteKeyEncrypt(der, key, iv)
+	if err != nil {
+		return nil, err
+	}
+
+	kdfParams, err := asn1.Marshal(pbkdf2Params{
+		Salt:       salt,
+		Iterations: PKCS8Iterations,
+		Prf:        pkix.AlgorithmIdentifier{Algorithm: oidHmacSha256, Parameters: asn1.NullRawValue},
+	})
+	if err != nil {
+		return nil, err
+	}
+	ivParams, err := asn1.Marshal(iv)
+	if err != nil {
+		return nil, err
+	}
+	params, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: pkix.AlgorithmIdentifier{Algorithm: oidPBKDF2, Parameters: asn1.RawValue{FullBytes: kdfParams}},
+		EncryptionScheme:  pkix.AlgorithmIdentifier{Algorithm: oidAes256Cbc, Parameters: asn1.RawValue{FullBytes: ivParams}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(encryptedPrivateKeyInfo{
+		Algo:          pkix.AlgorithmIdentifier{Algorithm: oidPBES2, Parameters: asn1.RawValue{FullBytes: params}},
+		EncryptedData: encrypted,
+	})
+}
+
+// DecryptPKCS8 解密 EncryptPKCS8 或 openssl 生成的加密 PKCS8 私钥，返回 PKCS8 DER
+func DecryptPKCS8(der, passphrase []byte) ([]byte, error) {
+	var info encryptedPrivateKeyInfo
+	if rest, err := asn1.Unmarshal(der, &info); err != nil || len(rest) > 0 {
+		return nil, ErrUnknownKey
+	}
+	if !info.Algo.Algorithm.Equal(oidPBES2) {
+		return nil, ErrUnsupportedPBES
+	}
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.Algo.Parameters.FullBytes, &params); err != nil {
+		return nil, ErrUnknownKey
+	}
+	if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, ErrUnsupportedPBES
+	}
+	var kdf pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdf); err != nil {
+		return nil, ErrUnknownKey
+	}
+
+	var prf func() hash.Hash
+	switch {
+	case len(kdf.Prf.Algorithm) == 0 || kdf.Prf.Algorithm.Equal(oidHmacSha1):
+		prf = sha1.New
+	case kdf.Prf.Algorithm.Equal(oidHmacSha256):
+		prf = sha256.New
+	default:
+		return nil, ErrUnsupportedPBES
+	}
+	var keyLen int
+	switch scheme := params.EncryptionScheme.Algorithm; {
+	case scheme.Equal(oidAes128Cbc):
+		keyLen = 16
+	case scheme.Equal(oidAes192Cbc):
+		keyLen = 24
+	case scheme.Equal(oidAes256Cbc):
+		keyLen = 32
+	default:
+		return nil, ErrUnsupportedPBES
+	}
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil || len(iv) != aes.BlockSize {
+		return nil, ErrUnknownKey
+	}
+	if kdf.Iterations <= 0 || len(info.EncryptedData) == 0 || len(info.EncryptedData)%aes.BlockSize != 0 {
+		return nil, ErrUnknownKey
+	}
+
+	key := pbkdf2.Key(passphrase, kdf.Salt, kdf.Iterations, keyLen, prf)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	plain := make([]byte, len(info.EncryptedData))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, info.EncryptedData)
+	plain, err = pkcs7Trimming(plain, aes.BlockSize)
+	if err != nil {
+		return nil, ErrPassphrase
+	}
+	// 填充碰巧有效时，解析 PKCS8 确认口令正确
+	var probe asn1.RawValue
+	if rest, err := asn1.Unmarshal(plain, &probe); err != nil || len(rest) > 0 {
+		return nil, ErrPassphrase
+	}
+	return plain, nil
+}
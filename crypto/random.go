@@ -0,0 +1,88 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"math/big"
+)
+
+// RandomString 常用的字符集
+const (
+	AlphabetDigits   = "0123456789"
+	AlphabetLower    = "abcdefghijklmnopqrstuvwxyz"
+	AlphabetUpper    = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	AlphabetAlnum    = AlphabetDigits + AlphabetLower + AlphabetUpper
+	AlphabetHex      = "0123456789abcdef"
+	AlphabetURLSafe  = AlphabetAlnum + "-_"
+	AlphabetReadable = "23456789abcdefghjkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ"
+)
+
+var ErrInvalidRange = errors.New("随机数范围无效")
+
+// RandomBytes 返回 n 个安全随机字节
+func RandomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// RandomInt 返回 [0, max) 内均匀分布的安全随机数
+func RandomInt(max int64) (int64, error) {
+	if max <= 0 {
+		return 0, ErrInvalidRange
+	}
+	n, err := rand.Int(rand.Reader, big.NewInt(max))
+	if err != nil {
+		return 0, err
+	}
+	return n.Int64(), nil
+}
+
+// RandomIntRange 返回 [min, max] 内均匀分布的安全随机数
+func RandomIntRange(min, max int64) (int64, error) {
+	if min > max || max-min+1 <= 0 {
+		return 0, ErrInvalidRange
+	}
+	n, err := RandomInt(max - min + 1)
+	if err != nil {
+		return 0, err
+	}
+	return min + n, nil
+}
+
+// RandomString 从 alphabet 中均匀选取 n 个字符，alphabet 按字节处理，最多 256 个字符
+func RandomString(n int, alphabet string) (string, error) {
+	if len(alphabet) == 0 || len(alphabet) > 256 {
+		return "", ErrInvalidRange
+	}
+	// 拒绝采样：丢弃超出 alphabet 整数倍的随机字节，避免取模造成的偏差
+	limit := 256 - 256%len(alphabet)
+	out := make([]byte, 0, n)
+	buf := make([]byte, n+n/4+8)
+	for len(out) < n {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for _, b := range buf {
+			if int(b) < limit {
+				out = append(out, alphabet[int(b)%len(alphabet)])
+				if len(out) == n {
+					break
+				}
+			}
+		}
+	}
+	return string(out), nil
+}
+
+// RandomToken 返回 n 个随机字节的 URL 安全 base64 编码（无填充），可用作会话或重置令牌
+func RandomToken(n int) (string, error) {
+	b, err := RandomBytes(n)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,57 @@
+package crypto_test
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/Jsharkc/mygopkg/crypto"
+)
+
+func TestRandom(t *testing.T) {
+	b, err := crypto.RandomBytes(32)
+	if err != nil || len(b) != 32 {
+		t.Fatal(err)
+	}
+
+	s, err := crypto.RandomString(1000, crypto.AlphabetDigits)
+	if err != nil || len(s) != 1000 || strings.Trim(s, crypto.AlphabetDigits) != "" {
+		t.Fatal(s, err)
+	}
+	// 每个数字都应该出现，粗略检查分布
+	for _, c := range crypto.AlphabetDigits {
+		if n := strings.Count(s, string(c)); n < 50 || n > 160 {
+			t.Error("distribution", string(c), n)
+		}
+	}
+	if _, err := crypto.RandomString(1, ""); err == nil {
+		t.Error("empty alphabet must fail")
+	}
+
+	for i := 0; i < 100; i++ {
+		n, err := crypto.RandomIntRange(-3, 3)
+		if err != nil || n < -3 || n > 3 {
+			t.Fatal(n, err)
+		}
+	}
+	if _, err := crypto.RandomInt(0); err == nil {
+		t.Error("zero max must fail")
+	}
+
+	token, err := crypto.RandomToken(24)
+	if err != nil || len(token) != 32 || strings.ContainsAny(token, "+/=") {
+		t.Error(token, err)
+	}
+	if _, err := base64.RawURLEncoding.DecodeString(token); err != nil {
+		t.Error(err)
+	}
+
+	key, err := crypto.AesGenerate(256)
+	if err != nil || len(key) != 32 {
+		t.Error("aes key", len(key), err)
+	}
+	iv, err := crypto.AesIvGenerate()
+	if err != nil || len(iv) != 16 {
+		t.Error("iv", len(iv), err)
+	}
+}
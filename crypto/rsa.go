@@ -4,7 +4,6 @@ import (
 	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
-	"crypto/sha512"
 	"crypto/x509"
 	"encoding/base64"
 )
@@ -33,37 +32,21 @@ func RsaGenerate(bits int) ([]byte, []byte, error) {
 // RSAOAEPEncrypt OAEP模式加密
 // label 是盐可以为任意值，用于验证解密结果
 func RSAOAEPEncrypt(target, publicKey, label []byte) ([]byte, error) {
-	pubKey, err := x509.ParsePKIXPublicKey(publicKey)
-	if err != nil {
-		return nil, err
-	}
-	rsaPubKey := pubKey.(*rsa.PublicKey)
-	data, err := rsa.EncryptOAEP(sha512.New(), rand.Reader, rsaPubKey, target, label)
-	if err != nil {
-		return nil, err
-	}
-
-	return data, nil
+	return RSAOAEPEncryptWithOptions(target, publicKey, RsaOaepOptions{Hash: crypto.SHA512, Label: label})
 }
 
 // RSAOAEPDecrypt OAEP模式解密
 // label需要使用与加密相同的值 是盐可以为任意值，用于验证解密结果
 func RSAOAEPDecrypt(target, privateKey, label []byte) ([]byte, error) {
-	privKey, err := x509.ParsePKCS8PrivateKey(privateKey)
-	if err != nil {
-		return nil, err
-	}
-	rsaPrivKey := privKey.(*rsa.PrivateKey)
-	return rsa.DecryptOAEP(sha512.New(), rand.Reader, rsaPrivKey, target, label)
+	return RSAOAEPDecryptWithOptions(target, privateKey, RsaOaepOptions{Hash: crypto.SHA512, Label: label})
 }
 
 // RSAEncrypt 普通模式加密
 func RSAEncrypt(target, publicKey []byte) ([]byte, error) {
-	pubKey, err := x509.ParsePKIXPublicKey(publicKey)
+	rsaPubKey, err := parseRsaPublicKey(publicKey)
 	if err != nil {
 		return nil, err
 	}
-	rsaPubKey := pubKey.(*rsa.PublicKey)
 	data, err := rsa.EncryptPKCS1v15(rand.Reader, rsaPubKey, target)
 	if err != nil {
 		return nil, err
@@ -74,11 +57,7 @@ func RSAEncrypt(target, publicKey []byte) ([]byte, error) {
 
 // RSADecrypt 普通模式解密
 func RSADecrypt(target, privateKey []byte) ([]byte, error) {
-	privKey, err := x509.ParsePKCS8PrivateKey(privateKey)
-	if err != nil {
-		return nil, err
-	}
-	rsaPrivKey := privKey.(*rsa.PrivateKey)
+	rsaPrivKey, err := parseRsaPrivateKey(privateKey)
 	if err != nil {
 		return nil, err
 	}
@@ -133,37 +112,15 @@ func RSADecryptBase64(target string, privateKey []byte) ([]byte, error) {
 
 // RSASign RSA签名
 // 输入target待签名数据
+// 使用 DefaultRsaSignOptions，其他摘要或填充方式见 RSASignWithOptions
 func RSASign(target, privateKey []byte) ([]byte, error) {
-	privKey, err := x509.ParsePKCS8PrivateKey(privateKey)
-	if err != nil {
-		return nil, err
-	}
-	msgHash := sha512.New()
-	_, err = msgHash.Write(target)
-	if err != nil {
-		return nil, err
-	}
-	msgHashSum := msgHash.Sum(nil)
-	rsaPrivKey := privKey.(*rsa.PrivateKey)
-
-	return rsa.SignPSS(rand.Reader, rsaPrivKey, crypto.SHA512, msgHashSum, &rsa.PSSOptions{SaltLength: 16, Hash: crypto.SHA512})
+	return RSASignWithOptions(target, privateKey, DefaultRsaSignOptions)
 }
 
 // RSAVerify RSA签名验证
 // 输入target待验证数据
 // sig为签名
+// 使用 DefaultRsaSignOptions，其他摘要或填充方式见 RSAVerifyWithOptions
 func RSAVerify(target, publicKey, sig []byte) error {
-	pubKey, err := x509.ParsePKIXPublicKey(publicKey)
-	if err != nil {
-		return err
-	}
-	rsaPubKey := pubKey.(*rsa.PublicKey)
-	msgHash := sha512.New()
-	_, err = msgHash.Write(target)
-	if err != nil {
-		return err
-	}
-	msgHashSum := msgHash.Sum(nil)
-
-	return rsa.VerifyPSS(rsaPubKey, crypto.SHA512, msgHashSum, sig, &rsa.PSSOptions{SaltLength: 16, Hash: crypto.SHA512})
+	return RSAVerifyWithOptions(target, publicKey, sig, DefaultRsaSignOptions)
 }
@@ -0,0 +1,225 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+	"math/big"
+
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// RsaPadding RSA 签名的填充方式
+type RsaPadding int
+
+const (
+	// RsaPSS RSASSA-PSS
+	RsaPSS RsaPadding = iota
+	// RsaPKCS1v15 RSASSA-PKCS1-v1_5
+	RsaPKCS1v15
+)
+
+// RsaSignOptions RSA 签名选项
+type RsaSignOptions struct {
+	// Hash 摘要算法，为 0 时使用 SHA512
+	Hash    crypto.Hash
+	Padding RsaPadding
+	// SaltLength PSS 盐长度，0 表示签名时取最大值、验证时自动识别，
+	// 也可以是 rsa.PSSSaltLengthEqualsHash
+	SaltLength int
+}
+
+// DefaultRsaSignOptions RSASign 和 RSAVerify 使用的选项：PSS、SHA512、盐长度 16
+var DefaultRsaSignOptions = RsaSignOptions{Hash: crypto.SHA512, Padding: RsaPSS, SaltLength: 16}
+
+// RsaOaepOptions RSA-OAEP 加密选项
+type RsaOaepOptions struct {
+	// Hash OAEP 摘要算法，为 0 时使用 SHA512
+	Hash crypto.Hash
+	// MGFHash MGF1 使用的摘要算法，为 0 时与 Hash 相同
+	MGFHash crypto.Hash
+	// Label 解密时必须相同
+	Label []byte
+}
+
+var ErrHashUnavailable = errors.New("摘要算法不可用")
+
+func (o RsaSignOptions) hash() (crypto.Hash, error) {
+	h := o.Hash
+	if h == 0 {
+		h = crypto.SHA512
+	}
+	if !h.Available() {
+		return 0, ErrHashUnavailable
+	}
+	return h, nil
+}
+
+func (o RsaOaepOptions) hashes() (crypto.Hash, crypto.Hash, error) {
+	h, mgf := o.Hash, o.MGFHash
+	if h == 0 {
+		h = crypto.SHA512
+	}
+	if mgf == 0 {
+		mgf = h
+	}
+	if !h.Available() || !mgf.Available() {
+		return 0, 0, ErrHashUnavailable
+	}
+	return h, mgf, nil
+}
+
+// RSASignWithOptions 按选项对 target 签名，私钥为 PKCS8
+func RSASignWithOptions(target, privateKey []byte, opts RsaSignOptions) ([]byte, error) {
+	h, err := opts.hash()
+	if err != nil {
+		return nil, err
+	}
+	digest := h.New()
+	digest.Write(target)
+	return rsaSignDigest(digest.Sum(nil), privateKey, h, opts)
+}
+
+// RSAVerifyWithOptions 按选项验证签名，公钥为 PKIX
+func RSAVerifyWithOptions(target, publicKey, sig []byte, opts RsaSignOptions) error {
+	h, err := opts.hash()
+	if err != nil {
+		return err
+	}
+	digest := h.New()
+	digest.Write(target)
+	return rsaVerifyDigest(digest.Sum(nil), publicKey, sig, h, opts)
+}
+
+// RSASignReader 流式读取 r 计算摘要并签名，适用于大文件
+func RSASignReader(r io.Reader, privateKey []byte, opts RsaSignOptions) ([]byte, error) {
+	h, err := opts.hash()
+	if err != nil {
+		return nil, err
+	}
+	digest := h.New()
+	if _, err := io.Copy(digest, r); err != nil {
+		return nil, err
+	}
+	return rsaSignDigest(digest.Sum(nil), privateKey, h, opts)
+}
+
+// RSAVerifyReader 流式读取 r 计算摘要并验证签名
+func RSAVerifyReader(r io.Reader, publicKey, sig []byte, opts RsaSignOptions) error {
+	h, err := opts.hash()
+	if err != nil {
+		return err
+	}
+	digest := h.New()
+	if _, err := io.Copy(digest, r); err != nil {
+		return err
+	}
+	return rsaVerifyDigest(digest.Sum(nil), publicKey, sig, h, opts)
+}
+
+func rsaSignDigest(digest, privateKey []byte, h crypto.Hash, opts RsaSignOptions) ([]byte, error) {
+	key, err := parseRsaPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	switch opts.Padding {
+	case RsaPSS:
+		return rsa.SignPSS(rand.Reader, key, h, digest, &rsa.PSSOptions{SaltLength: opts.SaltLength, Hash: h})
+	case RsaPKCS1v15:
+		return rsa.SignPKCS1v15(rand.Reader, key, h, digest)
+	}
+	return nil, errors.New("未知的 RSA 填充方式")
+}
+
+func rsaVerifyDigest(digest, publicKey, sig []byte, h crypto.Hash, opts RsaSignOptions) error {
+	key, err := parseRsaPublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+	switch opts.Padding {
+	case RsaPSS:
+		return rsa.VerifyPSS(key, h, digest, sig, &rsa.PSSOptions{SaltLength: opts.SaltLength, Hash: h})
+	case RsaPKCS1v15:
+		return rsa.VerifyPKCS1v15(key, h, digest, sig)
+	}
+	return errors.New("未知的 RSA 填充方式")
+}
+
+// RSAOAEPEncryptWithOptions 按选项 OAEP 加密，公钥为 PKIX
+func RSAOAEPEncryptWithOptions(target, publicKey []byte, opts RsaOaepOptions) ([]byte, error) {
+	key, err := parseRsaPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	h, mgf, err := opts.hashes()
+	if err != nil {
+		return nil, err
+	}
+	if h == mgf {
+		return rsa.EncryptOAEP(h.New(), rand.Reader, key, target, opts.Label)
+	}
+	return encryptOAEP(h.New(), mgf.New(), key, target, opts.Label)
+}
+
+// RSAOAEPDecryptWithOptions 按选项 OAEP 解密，私钥为 PKCS8
+func RSAOAEPDecryptWithOptions(target, privateKey []byte, opts RsaOaepOptions) ([]byte, error) {
+	key, err := parseRsaPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	h, mgf, err := opts.hashes()
+	if err != nil {
+		return nil, err
+	}
+	return key.Decrypt(rand.Reader, target, &rsa.OAEPOptions{Hash: h, MGFHash: mgf, Label: opts.Label})
+}
+
+// encryptOAEP OAEP 和 MGF1 使用不同摘要算法时的加密（RFC 8017 7.1.1），
+// 标准库从 Go 1.26 起才支持加密时单独指定 MGF1 的摘要算法
+func encryptOAEP(h, mgfHash hash.Hash, pub *rsa.PublicKey, msg, label []byte) ([]byte, error) {
+	k := pub.Size()
+	hLen := h.Size()
+	if len(msg) > k-2*hLen-2 {
+		return nil, rsa.ErrMessageTooLong
+	}
+
+	h.Write(label)
+	lHash := h.Sum(nil)
+
+	em := make([]byte, k)
+	seed := em[1 : 1+hLen]
+	db := em[1+hLen:]
+	copy(db, lHash)
+	db[len(db)-len(msg)-1] = 1
+	copy(db[len(db)-len(msg):], msg)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, err
+	}
+	mgf1Xor(db, mgfHash, seed)
+	mgf1Xor(seed, mgfHash, db)
+
+	m := new(big.Int).SetBytes(em)
+	c := new(big.Int).Exp(m, big.NewInt(int64(pub.E)), pub.N)
+	return c.FillBytes(make([]byte, k)), nil
+}
+
+// mgf1Xor 把 MGF1(seed) 异或到 out 上
+func mgf1Xor(out []byte, h hash.Hash, seed []byte) {
+	var counter [4]byte
+	for done := 0; done < len(out); {
+		h.Reset()
+		h.Write(seed)
+		h.Write(counter[:])
+		mask := h.Sum(nil)
+		n := subtle.XORBytes(out[done:], out[done:], mask)
+		done += n
+		binary.BigEndian.PutUint32(counter[:], binary.BigEndian.Uint32(counter[:])+1)
+	}
+}
@@ -0,0 +1,106 @@
+package crypto_test
+
+import (
+	stdcrypto "crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"strings"
+	"testing"
+
+	"github.com/Jsharkc/mygopkg/crypto"
+)
+
+func TestRSASignOptions(t *testing.T) {
+	priv, pub, err := crypto.RsaGenerate(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(strings.Repeat("payload", 1000))
+
+	cases := []crypto.RsaSignOptions{
+		crypto.DefaultRsaSignOptions,
+		{Hash: stdcrypto.SHA256, Padding: crypto.RsaPKCS1v15},
+		{Hash: stdcrypto.SHA256, Padding: crypto.RsaPSS, SaltLength: rsa.PSSSaltLengthEqualsHash},
+		{Hash: stdcrypto.SHA384, Padding: crypto.RsaPSS},
+	}
+	for _, opts := range cases {
+		sig, err := crypto.RSASignWithOptions(msg, priv, opts)
+		if err != nil {
+			t.Fatal(opts, err)
+		}
+		if err := crypto.RSAVerifyWithOptions(msg, pub, sig, opts); err != nil {
+			t.Error(opts, err)
+		}
+		if err := crypto.RSAVerifyReader(strings.NewReader(string(msg)), pub, sig, opts); err != nil {
+			t.Error(opts, "reader", err)
+		}
+		streamed, err := crypto.RSASignReader(strings.NewReader(string(msg)), priv, opts)
+		if err != nil || crypto.RSAVerifyWithOptions(msg, pub, streamed, opts) != nil {
+			t.Error(opts, "sign reader", err)
+		}
+		if err := crypto.RSAVerifyWithOptions(msg[1:], pub, sig, opts); err == nil {
+			t.Error(opts, "other message must fail")
+		}
+	}
+
+	// 与对方使用标准库 SHA256 PKCS1v15 签名互通
+	key, _ := x509.ParsePKCS8PrivateKey(priv)
+	digest := sha256.Sum256(msg)
+	sig, _ := rsa.SignPKCS1v15(rand.Reader, key.(*rsa.PrivateKey), stdcrypto.SHA256, digest[:])
+	if err := crypto.RSAVerifyWithOptions(msg, pub, sig, crypto.RsaSignOptions{Hash: stdcrypto.SHA256, Padding: crypto.RsaPKCS1v15}); err != nil {
+		t.Error("interop", err)
+	}
+	if err := crypto.RSAVerify(msg, pub, sig); err == nil {
+		t.Error("default options must not accept PKCS1v15 SHA256")
+	}
+}
+
+func TestRSAOAEPOptions(t *testing.T) {
+	priv, pub, err := crypto.RsaGenerate(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := x509.ParsePKCS8PrivateKey(priv)
+	rsaKey := key.(*rsa.PrivateKey)
+
+	cases := []crypto.RsaOaepOptions{
+		{Hash: stdcrypto.SHA256},
+		{Hash: stdcrypto.SHA256, MGFHash: stdcrypto.SHA1, Label: []byte("l")},
+		{Hash: stdcrypto.SHA1},
+		{},
+	}
+	for _, opts := range cases {
+		encrypted, err := crypto.RSAOAEPEncryptWithOptions([]byte("secret"), pub, opts)
+		if err != nil {
+			t.Fatal(opts, err)
+		}
+		plain, err := crypto.RSAOAEPDecryptWithOptions(encrypted, priv, opts)
+		if err != nil || string(plain) != "secret" {
+			t.Error(opts, err)
+		}
+	}
+
+	// OAEP SHA256 + MGF1 SHA1 与标准库解密互通
+	opts := crypto.RsaOaepOptions{Hash: stdcrypto.SHA256, MGFHash: stdcrypto.SHA1}
+	encrypted, _ := crypto.RSAOAEPEncryptWithOptions([]byte("partner"), pub, opts)
+	plain, err := rsaKey.Decrypt(nil, encrypted, &rsa.OAEPOptions{Hash: stdcrypto.SHA256, MGFHash: stdcrypto.SHA1})
+	if err != nil || string(plain) != "partner" {
+		t.Error("interop", err)
+	}
+	if _, err := crypto.RSAOAEPDecryptWithOptions(encrypted, priv, crypto.RsaOaepOptions{Hash: stdcrypto.SHA256}); err == nil {
+		t.Error("mgf hash mismatch must fail")
+	}
+
+	// 默认的 RSAOAEPEncrypt 保持 SHA512
+	encrypted, _ = crypto.RSAOAEPEncrypt([]byte("old"), pub, nil)
+	if plain, err := crypto.RSAOAEPDecryptWithOptions(encrypted, priv, crypto.RsaOaepOptions{Hash: stdcrypto.SHA512}); err != nil || string(plain) != "old" {
+		t.Error("default", err)
+	}
+	encrypted, _ = rsa.EncryptOAEP(sha1.New(), rand.Reader, &rsaKey.PublicKey, []byte("sha1"), nil)
+	if plain, err := crypto.RSAOAEPDecryptWithOptions(encrypted, priv, crypto.RsaOaepOptions{Hash: stdcrypto.SHA1}); err != nil || string(plain) != "sha1" {
+		t.Error("sha1", err)
+	}
+}
@@ -0,0 +1,320 @@
+package crypto
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+)
+
+// 流式加密格式（STREAM 构造）：
+// 头部：版本(1字节) | 算法(1字节) | 分块大小(4字节) | 盐(16字节)
+// 之后是若干密文分块，每块由 HKDF(密钥, 盐) 派生的流密钥加密，
+// nonce 为 0... | 分块序号(4字节) | 最后一块标记(1字节)，头部作为附加认证数据，
+// 因此分块无法被调换、删除或截断
+const (
+	StreamVersion    = 1
+	DefaultChunkSize = 64 * 1024
+
+	streamSaltSize   = 16
+	streamHeaderSize = 2 + 4 + streamSaltSize
+	maxChunkSize     = 16 * 1024 * 1024
+)
+
+var (
+	ErrStreamHeader    = errors.New("流式密文头部无效")
+	ErrStreamTruncated = errors.New("流式密文被截断")
+	ErrStreamTooLong   = errors.New("流式加密的分块数量超出上限")
+	ErrStreamKeySize   = errors.New("主密钥长度无效：AES-GCM 需要 16、24 或 32 字节，ChaCha20-Poly1305 需要 32 字节")
+)
+
+// streamAead 由主密钥和盐派生本次流使用的 AEAD
+func streamAead(alg AeadAlg, key, salt []byte) (cipher.AEAD, error) {
+	// HKDF 接受任意长度的输入，这里先检查主密钥，避免弱密钥被悄悄接受
+	switch alg {
+	case AeadAesGcm:
+		if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+			return nil, ErrStreamKeySize
+		}
+	case AeadChaCha20Poly1305:
+		if len(key) != chacha20poly1305.KeySize {
+			return nil, ErrStreamKeySize
+		}
+	default:
+		return nil, ErrUnknownAeadAlg
+	}
+	streamKey := make([]byte, 32)
+	if alg == AeadAesGcm && len(key) < 32 {
+		streamKey = streamKey[:len(key)]
+	}
+	if _, err := io.ReadFull(hkdf.New(sha256.New, key, salt, []byte("mygopkg stream")), streamKey); err != nil {
+		return nil, err
+	}
+	return NewAead(alg, streamKey)
+}
+
+// streamNonce 生成第 counter 块的 nonce
+func streamNonce(nonce []byte, counter uint32, last bool) {
+	clear(nonce)
+	binary.BigEndian.PutUint32(nonce[len(nonce)-5:], counter)
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+}
+
+// EncryptWriter 流式加密，写入的明文按块加密后写到下层 io.Writer
+// 必须调用 Close 写出最后一块，否则解密时视为截断
+type EncryptWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	header  []byte
+	nonce   []byte
+	buf     []byte
+	out     []byte
+	counter uint32
+	closed  bool
+}
+
+// NewEncryptWriter 创建流式加密 Writer，分块大小为 DefaultChunkSize
+func NewEncryptWriter(w io.Writer, alg AeadAlg, key []byte) (*EncryptWriter, error) {
+	return NewEncryptWriterSize(w, alg, key, DefaultChunkSize)
+}
+
+// NewEncryptWriterSize 创建指定分块大小的流式加密 Writer
+func NewEncryptWriterSize(w io.Writer, alg AeadAlg, key []byte, chunkSize int) (*EncryptWriter, error) {
+	if chunkSize <= 0 || chunkSize > maxChunkSize {
+		return nil, errors.New("分块大小无效")
+	}
+	header := make([]byte, streamHeaderSize)
+	header[0], header[1] = StreamVersion, byte(alg)
+	binary.BigEndian.PutUint32(header[2:6], uint32(chunkSize))
+	if _, err := rand.Read(header[6:]); err != nil {
+		return nil, err
+	}
+	aead, err := streamAead(alg, key, header[6:])
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &EncryptWriter{
+		w:      w,
+		aead:   aead,
+		header: header,
+		nonce:  make([]byte, aead.NonceSize()),
+		buf:    make([]byte, 0, chunkSize),
+		out:    make([]byte, 0, chunkSize+aead.Overhead()),
+	}, nil
+}
+
+func (e *EncryptWriter) Write(p []byte) (int, error) {
+	if e.closed {
+		return 0, os.ErrClosed
+	}
+	n := 0
+	for len(p) > 0 {
+		// 缓冲区满且还有数据时，才能确定当前块不是最后一块
+		if len(e.buf) == cap(e.buf) {
+			if err := e.flush(false); err != nil {
+				return n, err
+			}
+		}
+		c := copy(e.buf[len(e.buf):cap(e.buf)], p)
+		e.buf = e.buf[:len(e.buf)+c]
+		p = p[c:]
+		n += c
+	}
+	return n, nil
+}
+
+func (e *EncryptWriter) flush(last bool) error {
+	if e.counter == math.MaxUint32 {
+		return ErrStreamTooLong
+	}
+	streamNonce(e.nonce, e.counter, last)
+	e.out = e.aead.Seal(e.out[:0], e.nonce, e.buf, e.header)
+	if _, err := e.w.Write(e.out); err != nil {
+		return err
+	}
+	e.counter++
+	e.buf = e.buf[:0]
+	return nil
+}
+
+// Close 加密并写出最后一块，不会关闭下层 io.Writer
+func (e *EncryptWriter) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	return e.flush(true)
+}
+
+// DecryptReader 流式解密 EncryptWriter 的输出，每块通过认证后才返回明文
+type DecryptReader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	header  []byte
+	nonce   []byte
+	in      []byte
+	out     []byte
+	plain   []byte
+	counter uint32
+	done    bool
+	err     error
+}
+
+// NewDecryptReader 读取头部并创建流式解密 Reader
+func NewDecryptReader(r io.Reader, key []byte) (*DecryptReader, error) {
+	header := make([]byte, streamHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, ErrStreamHeader
+	}
+	chunkSize := binary.BigEndian.Uint32(header[2:6])
+	if header[0] != StreamVersion || chunkSize == 0 || chunkSize > maxChunkSize {
+		return nil, ErrStreamHeader
+	}
+	aead, err := streamAead(AeadAlg(header[1]), key, header[6:])
+	if err != nil {
+		return nil, err
+	}
+	return &DecryptReader{
+		r:      bufio.NewReader(r),
+		aead:   aead,
+		header: header,
+		nonce:  make([]byte, aead.NonceSize()),
+		in:     make([]byte, int(chunkSize)+aead.Overhead()),
+		out:    make([]byte, 0, chunkSize),
+	}, nil
+}
+
+func (d *DecryptReader) Read(p []byte) (int, error) {
+	for len(d.plain) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		if d.done {
+			return 0, io.EOF
+		}
+		d.err = d.next()
+	}
+	n := copy(p, d.plain)
+	d.plain = d.plain[n:]
+	return n, nil
+}
+
+// next 读取并解密下一块，读不满一块或之后没有数据时就是最后一块
+func (d *DecryptReader) next() error {
+	n, err := io.ReadFull(d.r, d.in)
+	last := false
+	switch {
+	case err == io.EOF:
+		return ErrStreamTruncated
+	case err == io.ErrUnexpectedEOF:
+		last = true
+	case err != nil:
+		return err
+	default:
+		if _, err := d.r.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	streamNonce(d.nonce, d.counter, last)
+	// 不能原地解密：认证失败时输出会被清零，下面还要用原密文判断是否被截断
+	plain, err := d.aead.Open(d.out[:0], d.nonce, d.in[:n], d.header)
+	if err != nil {
+		if last {
+			// 最后一块标记不符，说明末尾的分块被删除了
+			streamNonce(d.nonce, d.counter, false)
+			if _, err := d.aead.Open(nil, d.nonce, d.in[:n], d.header); err == nil {
+				return ErrStreamTruncated
+			}
+		}
+		return err
+	}
+	d.counter++
+	d.plain = plain
+	d.done = last
+	return nil
+}
+
+// EncryptFile 流式加密文件，先写入同目录的临时文件，成功后再原子替换 dst
+func EncryptFile(src, dst string, alg AeadAlg, key []byte) error {
+	return transformFile(src, dst, func(w io.Writer, r io.Reader) error {
+		ew, err := NewEncryptWriter(w, alg, key)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(ew, r); err != nil {
+			return err
+		}
+		return ew.Close()
+	})
+}
+
+// DecryptFile 流式解密 EncryptFile 生成的文件，全部认证通过后才替换 dst，
+// 失败时 dst 保持不变
+func DecryptFile(src, dst string, key []byte) error {
+	return transformFile(src, dst, func(w io.Writer, r io.Reader) error {
+		dr, err := NewDecryptReader(r, key)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(w, dr)
+		return err
+	})
+}
+
+func transformFile(src, dst string, transform func(w io.Writer, r io.Reader) error) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	w := bufio.NewWriterSize(tmp, DefaultChunkSize)
+	if err = transform(w, bufio.NewReaderSize(in, DefaultChunkSize)); err != nil {
+		return err
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
@@ -0,0 +1,156 @@
+package crypto_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Jsharkc/mygopkg/crypto"
+)
+
+func encryptStream(t *testing.T, alg crypto.AeadAlg, key, plain []byte, chunkSize int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := crypto.NewEncryptWriterSize(&buf, alg, key, chunkSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 分多次写入，覆盖跨块的情况
+	for i := 0; i < len(plain); i += 7 {
+		end := min(i+7, len(plain))
+		if _, err := w.Write(plain[i:end]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func decryptStream(key, encrypted []byte) ([]byte, error) {
+	r, err := crypto.NewDecryptReader(bytes.NewReader(encrypted), key)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestStream(t *testing.T) {
+	key := bytes.Repeat([]byte{3}, 32)
+	for _, alg := range []crypto.AeadAlg{crypto.AeadAesGcm, crypto.AeadChaCha20Poly1305} {
+		for _, size := range []int{0, 1, 15, 16, 17, 32, 100} {
+			plain := bytes.Repeat([]byte("0123456789"), 10)[:size]
+			encrypted := encryptStream(t, alg, key, plain, 16)
+			got, err := decryptStream(key, encrypted)
+			if err != nil || !bytes.Equal(got, plain) {
+				t.Error(alg, size, err)
+			}
+		}
+	}
+}
+
+func TestStreamTamper(t *testing.T) {
+	key := bytes.Repeat([]byte{4}, 32)
+	plain := bytes.Repeat([]byte("a"), 64)
+	encrypted := encryptStream(t, crypto.AeadAesGcm, key, plain, 16)
+	chunk := 16 + 16
+	header := len(encrypted) - 4*chunk
+
+	// 去掉最后一块
+	if _, err := decryptStream(key, encrypted[:len(encrypted)-chunk]); !errors.Is(err, crypto.ErrStreamTruncated) {
+		t.Error("drop final chunk", err)
+	}
+	// 在块边界截断
+	if _, err := decryptStream(key, encrypted[:header+2*chunk]); !errors.Is(err, crypto.ErrStreamTruncated) {
+		t.Error("truncate at boundary", err)
+	}
+	// 交换两块
+	swapped := append([]byte(nil), encrypted...)
+	copy(swapped[header:], encrypted[header+chunk:header+2*chunk])
+	copy(swapped[header+chunk:], encrypted[header:header+chunk])
+	if _, err := decryptStream(key, swapped); err == nil {
+		t.Error("reordered chunks must fail")
+	}
+	// 修改头部
+	modified := append([]byte(nil), encrypted...)
+	modified[5]++
+	if _, err := decryptStream(key, modified); err == nil {
+		t.Error("modified header must fail")
+	}
+	if _, err := decryptStream(bytes.Repeat([]byte{5}, 32), encrypted); err == nil {
+		t.Error("wrong key must fail")
+	}
+	if _, err := decryptStream(key, encrypted[:5]); !errors.Is(err, crypto.ErrStreamHeader) {
+		t.Error("short header", err)
+	}
+}
+
+func TestStreamKeySize(t *testing.T) {
+	cases := []struct {
+		alg crypto.AeadAlg
+		n   int
+		ok  bool
+	}{
+		{crypto.AeadAesGcm, 16, true},
+		{crypto.AeadAesGcm, 24, true},
+		{crypto.AeadAesGcm, 32, true},
+		{crypto.AeadAesGcm, 0, false},
+		{crypto.AeadAesGcm, 8, false},
+		{crypto.AeadChaCha20Poly1305, 32, true},
+		{crypto.AeadChaCha20Poly1305, 16, false},
+	}
+	for _, c := range cases {
+		_, err := crypto.NewEncryptWriter(io.Discard, c.alg, make([]byte, c.n))
+		if c.ok != (err == nil) || !c.ok && !errors.Is(err, crypto.ErrStreamKeySize) {
+			t.Error(c.alg, c.n, err)
+		}
+	}
+
+	// 解密时同样检查
+	encrypted := encryptStream(t, crypto.AeadChaCha20Poly1305, bytes.Repeat([]byte{7}, 32), []byte("hello"), 16)
+	if _, err := decryptStream([]byte("short"), encrypted); !errors.Is(err, crypto.ErrStreamKeySize) {
+		t.Error("short key", err)
+	}
+}
+
+func TestEncryptFile(t *testing.T) {
+	dir := t.TempDir()
+	key := bytes.Repeat([]byte{6}, 16)
+	src := filepath.Join(dir, "plain.bin")
+	enc := filepath.Join(dir, "plain.bin.enc")
+	dst := filepath.Join(dir, "out.bin")
+
+	plain := bytes.Repeat([]byte("recording"), 20000)
+	if err := os.WriteFile(src, plain, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := crypto.EncryptFile(src, enc, crypto.AeadAesGcm, key); err != nil {
+		t.Fatal(err)
+	}
+	if err := crypto.DecryptFile(enc, dst, key); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(dst)
+	if !bytes.Equal(got, plain) {
+		t.Error("file content")
+	}
+
+	// 解密失败时不能覆盖已有文件，也不能留下临时文件
+	if err := os.WriteFile(dst, []byte("keep"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := crypto.DecryptFile(enc, dst, bytes.Repeat([]byte{7}, 16)); err == nil {
+		t.Error("wrong key must fail")
+	}
+	if got, _ := os.ReadFile(dst); string(got) != "keep" {
+		t.Error("dst must be unchanged")
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 3 {
+		t.Error("temporary file left", len(entries))
+	}
+}
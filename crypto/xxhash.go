@@ -0,0 +1,120 @@
+package crypto
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+// xxHash64 常量
+const (
+	xxPrime1 uint64 = 11400714785074694791
+	xxPrime2 uint64 = 14029467366897019727
+	xxPrime3 uint64 = 1609587929392839161
+	xxPrime4 uint64 = 9650029242287828579
+	xxPrime5 uint64 = 2870177450012600261
+)
+
+// xxhash64 xxHash64 的流式实现，种子为 0
+type xxhash64 struct {
+	v     [4]uint64
+	total uint64
+	buf   [32]byte
+	n     int
+}
+
+// NewXxhash64 创建 xxHash64（种子 0），Sum 为大端序 8 字节
+func NewXxhash64() hash.Hash64 {
+	d := &xxhash64{}
+	d.Reset()
+	return d
+}
+
+func (d *xxhash64) Reset() {
+	// 用变量计算，常量表达式溢出无法编译，这里需要按 uint64 回绕
+	p1 := xxPrime1
+	d.v = [4]uint64{p1 + xxPrime2, xxPrime2, 0, -p1}
+	d.total = 0
+	d.n = 0
+}
+
+func (d *xxhash64) Size() int      { return 8 }
+func (d *xxhash64) BlockSize() int { return 32 }
+
+func xxRound(acc, input uint64) uint64 {
+	acc += input * xxPrime2
+	return bits.RotateLeft64(acc, 31) * xxPrime1
+}
+
+func xxMergeRound(acc, val uint64) uint64 {
+	acc ^= xxRound(0, val)
+	return acc*xxPrime1 + xxPrime4
+}
+
+func (d *xxhash64) stripe(b []byte) {
+	d.v[0] = xxRound(d.v[0], binary.LittleEndian.Uint64(b[0:]))
+	d.v[1] = xxRound(d.v[1], binary.LittleEndian.Uint64(b[8:]))
+	d.v[2] = xxRound(d.v[2], binary.LittleEndian.Uint64(b[16:]))
+	d.v[3] = xxRound(d.v[3], binary.LittleEndian.Uint64(b[24:]))
+}
+
+func (d *xxhash64) Write(p []byte) (int, error) {
+	n := len(p)
+	d.total += uint64(n)
+	if d.n > 0 {
+		c := copy(d.buf[d.n:], p)
+		d.n += c
+		p = p[c:]
+		if d.n < 32 {
+			return n, nil
+		}
+		d.stripe(d.buf[:])
+		d.n = 0
+	}
+	for ; len(p) >= 32; p = p[32:] {
+		d.stripe(p)
+	}
+	d.n = copy(d.buf[:], p)
+	return n, nil
+}
+
+func (d *xxhash64) Sum64() uint64 {
+	var h uint64
+	if d.total >= 32 {
+		v := d.v
+		h = bits.RotateLeft64(v[0], 1) + bits.RotateLeft64(v[1], 7) +
+			bits.RotateLeft64(v[2], 12) + bits.RotateLeft64(v[3], 18)
+		for _, x := range v {
+			h = xxMergeRound(h, x)
+		}
+	} else {
+		h = xxPrime5
+	}
+	h += d.total
+
+	p := d.buf[:d.n]
+	for ; len(p) >= 8; p = p[8:] {
+		h ^= xxRound(0, binary.LittleEndian.Uint64(p))
+		h = bits.RotateLeft64(h, 27)*xxPrime1 + xxPrime4
+	}
+	if len(p) >= 4 {
+		h ^= uint64(binary.LittleEndian.Uint32(p)) * xxPrime1
+		h = bits.RotateLeft64(h, 23)*xxPrime2 + xxPrime3
+		p = p[4:]
+	}
+	for _, b := range p {
+		h ^= uint64(b) * xxPrime5
+		h = bits.RotateLeft64(h, 11) * xxPrime1
+	}
+
+	h ^= h >> 33
+	h *= xxPrime2
+	h ^= h >> 29
+	h *= xxPrime3
+	h ^= h >> 32
+	return h
+}
+
+func (d *xxhash64) Sum(b []byte) []byte {
+	return binary.BigEndian.AppendUint64(b, d.Sum64())
+}
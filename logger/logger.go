@@ -2,26 +2,33 @@ package logger
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 var (
 	DefaultAppsName = "myapp"
 	// Global logger instance
 	DefaultLogger *Logger
+
+	// resources owned by DefaultLogger, released by Close
+	closers []io.Closer
 )
 
 // Logger wraps zap logger with additional functionality
 type Logger struct {
 	*zap.SugaredLogger
+	// ctx whose fields the logger already carries, see WithContext
+	ctx context.Context
 }
 
 // LogConfig defines the configuration for the logger
@@ -36,6 +43,12 @@ type LogConfig struct {
 	MaxAge int
 	// Whether to compress old log files
 	Compress bool
+	// Rotation mode: size (default), daily or hourly
+	RotationMode string
+	// Symlink kept pointing at the current file in daily/hourly mode, e.g. "latest"
+	RotationLink string
+	// Hook receiving the path of every archived file in daily/hourly mode
+	OnRotate func(path string)
 	// Minimum logging level
 	Level string
 	// Whether to also log to console
@@ -44,6 +57,15 @@ type LogConfig struct {
 	AppName string
 	// Console encoder config
 	ConsoleEncoderConfig string
+	// Outputs with their own level range, encoder and rotation; when empty a single
+	// file output plus an optional console output are built from the fields above
+	Outputs []OutputConfig
+	// Sampling of entries sharing a level and message template, nil disables it
+	Sampling *SamplingConfig
+	// Collapsing of repeated identical messages, nil disables it
+	Suppression *SuppressionConfig
+	// Redaction of sensitive fields and patterns, nil disables it
+	Mask *MaskConfig
 }
 
 // DefaultConfig returns the default logging configuration
@@ -104,12 +126,11 @@ func InitLogger(config LogConfig) error {
 
 	fmt.Println("Logger config.FileDir", config.FileDir)
 
-	fileRotator := &lumberjack.Logger{
-		Filename:   filepath.Join(config.FileDir, config.AppName+".log"),
-		MaxSize:    config.MaxSize,
-		MaxBackups: config.MaxBackups,
-		MaxAge:     config.MaxAge,
-		Compress:   config.Compress,
+	var masker *Masker
+	if config.Mask != nil {
+		if masker, err = NewMasker(*config.Mask); err != nil {
+			return err
+		}
 	}
 
 	// Parse log level
@@ -119,50 +140,35 @@ func InitLogger(config LogConfig) error {
 		return err
 	}
 
-	// Create cores
+	// Create one core per output
 	var cores []zapcore.Core
-
-	// File core - use custom formatter
-	fileCore := zapcore.NewCore(
-		NewFormatterEncoder(),
-		zapcore.AddSync(fileRotator),
-		level,
-	)
-	cores = append(cores, fileCore)
-
-	// Console core (if enabled)
-	if config.EnableConsole {
-		consoleEncoderConfig := zap.NewDevelopmentEncoderConfig()
-		consoleEncoderConfig.TimeKey = "time"
-		consoleEncoderConfig.LevelKey = "level"
-		consoleEncoderConfig.NameKey = "logger"
-		consoleEncoderConfig.CallerKey = "caller"
-		consoleEncoderConfig.MessageKey = "msg"
-		consoleEncoderConfig.StacktraceKey = "stacktrace"
-		consoleEncoderConfig.LineEnding = zapcore.DefaultLineEnding
-		consoleEncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
-		consoleEncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-		consoleEncoderConfig.EncodeDuration = zapcore.StringDurationEncoder
-		consoleEncoderConfig.EncodeCaller = projectRootCallerEncoder
-		var consoleCore zapcore.Core
-		if config.ConsoleEncoderConfig == "development" {
-			consoleCore = zapcore.NewCore(
-				zapcore.NewConsoleEncoder(consoleEncoderConfig),
-				zapcore.AddSync(os.Stdout),
-				level,
-			)
-		} else {
-			consoleCore = zapcore.NewCore(
-				NewFormatterEncoder(),
-				zapcore.AddSync(os.Stdout),
-				level,
-			)
+	var outputClosers []io.Closer
+	for _, out := range config.outputs() {
+		core, closer, err := newOutputCore(config, out, level)
+		if err != nil {
+			for _, c := range outputClosers {
+				_ = c.Close()
+			}
+			return err
+		}
+		cores = append(cores, core)
+		if closer != nil {
+			outputClosers = append(outputClosers, closer)
 		}
-		cores = append(cores, consoleCore)
 	}
 
+	// Release resources held by a previously initialized logger
+	_ = closeResources()
+	closers = outputClosers
+
 	// Create logger with caller skip
-	core := zapcore.NewTee(cores...)
+	core := newTeeCore(cores...)
+	core = newMaskCore(core, masker)
+	core = newSamplerCore(core, config.Sampling)
+	core, suppressor := newSuppressCore(core, config.Suppression)
+	if suppressor != nil {
+		closers = append(closers, suppressor)
+	}
 	zapLogger := zap.New(core,
 		zap.AddCaller(),
 		zap.AddCallerSkip(1), // caller skip
@@ -229,7 +235,17 @@ func Fatalf(format string, args ...interface{}) {
 
 // WithFields adds structured fields to the logging context
 func WithFields(fields map[string]any) *Logger {
-	return &Logger{DefaultLogger.With(fields)}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	args := make([]interface{}, 0, len(fields))
+	for _, k := range keys {
+		args = append(args, zap.Any(k, fields[k]))
+	}
+	return &Logger{SugaredLogger: DefaultLogger.With(args...)}
 }
 
 // Sync flushes any buffered log entries
@@ -237,6 +253,25 @@ func Sync() error {
 	return DefaultLogger.Sync()
 }
 
+// Close flushes DefaultLogger and releases the resources opened by InitLogger
+func Close() error {
+	if DefaultLogger != nil {
+		_ = DefaultLogger.Sync()
+	}
+	return closeResources()
+}
+
+func closeResources() error {
+	var errs []error
+	for _, c := range closers {
+		if err := c.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	closers = nil
+	return errors.Join(errs...)
+}
+
 // Trace logs a message at trace level (mapped to debug since zap doesn't have trace)
 func (l *Logger) Trace(v ...interface{}) {
 	l.Debug(v...)
@@ -257,37 +292,86 @@ func (l *Logger) Noticef(format string, v ...interface{}) {
 	l.Infof(format, v...)
 }
 
-// CtxTracef logs a formatted message at trace level with context
+// WithContext returns l with the fields carried by ctx: trace and user IDs,
+// fields added by WithCtxFields and the values of registered context keys;
+// l is returned as is when it already carries them, like the result of FromContext(ctx)
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	if l.ctx == ctx {
+		return l
+	}
+	fields := contextFields(ctx)
+	if len(fields) == 0 {
+		return l
+	}
+	return &Logger{SugaredLogger: l.With(fields...), ctx: ctx}
+}
+
+// CtxTracef logs a formatted message at trace level with the fields carried by ctx
 func (l *Logger) CtxTracef(ctx context.Context, format string, v ...interface{}) {
-	FromContext(ctx).Debugf(format, v...)
+	l.WithContext(ctx).Debugf(format, v...)
 }
 
-// CtxDebugf logs a formatted message at debug level with context
+// CtxDebugf logs a formatted message at debug level with the fields carried by ctx
 func (l *Logger) CtxDebugf(ctx context.Context, format string, v ...interface{}) {
-	FromContext(ctx).Debugf(format, v...)
+	l.WithContext(ctx).Debugf(format, v...)
 }
 
-// CtxInfof logs a formatted message at info level with context
+// CtxInfof logs a formatted message at info level with the fields carried by ctx
 func (l *Logger) CtxInfof(ctx context.Context, format string, v ...interface{}) {
-	FromContext(ctx).Infof(format, v...)
+	l.WithContext(ctx).Infof(format, v...)
 }
 
-// CtxNoticef logs a formatted message at notice level with context (mapped to info)
+// CtxNoticef logs a formatted message at notice level with the fields carried by ctx (mapped to info)
 func (l *Logger) CtxNoticef(ctx context.Context, format string, v ...interface{}) {
-	FromContext(ctx).Infof(format, v...)
+	l.WithContext(ctx).Infof(format, v...)
 }
 
-// CtxWarnf logs a formatted message at warn level with context
+// CtxWarnf logs a formatted message at warn level with the fields carried by ctx
 func (l *Logger) CtxWarnf(ctx context.Context, format string, v ...interface{}) {
-	FromContext(ctx).Warnf(format, v...)
+	l.WithContext(ctx).Warnf(format, v...)
 }
 
-// CtxErrorf logs a formatted message at error level with context
+// CtxErrorf logs a formatted message at error level with the fields carried by ctx
 func (l *Logger) CtxErrorf(ctx context.Context, format string, v ...interface{}) {
-	FromContext(ctx).Errorf(format, v...)
+	l.WithContext(ctx).Errorf(format, v...)
 }
 
-// CtxFatalf logs a formatted message at fatal level with context
+// CtxFatalf logs a formatted message at fatal level with the fields carried by ctx
 func (l *Logger) CtxFatalf(ctx context.Context, format string, v ...interface{}) {
+	l.WithContext(ctx).Fatalf(format, v...)
+}
+
+// CtxTracef logs a formatted message at trace level with the logger of ctx (mapped to debug)
+func CtxTracef(ctx context.Context, format string, v ...interface{}) {
+	FromContext(ctx).Debugf(format, v...)
+}
+
+// CtxDebugf logs a formatted message at debug level with the logger of ctx
+func CtxDebugf(ctx context.Context, format string, v ...interface{}) {
+	FromContext(ctx).Debugf(format, v...)
+}
+
+// CtxInfof logs a formatted message at info level with the logger of ctx
+func CtxInfof(ctx context.Context, format string, v ...interface{}) {
+	FromContext(ctx).Infof(format, v...)
+}
+
+// CtxNoticef logs a formatted message at notice level with the logger of ctx (mapped to info)
+func CtxNoticef(ctx context.Context, format string, v ...interface{}) {
+	FromContext(ctx).Infof(format, v...)
+}
+
+// CtxWarnf logs a formatted message at warn level with the logger of ctx
+func CtxWarnf(ctx context.Context, format string, v ...interface{}) {
+	FromContext(ctx).Warnf(format, v...)
+}
+
+// CtxErrorf logs a formatted message at error level with the logger of ctx
+func CtxErrorf(ctx context.Context, format string, v ...interface{}) {
+	FromContext(ctx).Errorf(format, v...)
+}
+
+// CtxFatalf logs a formatted message at fatal level with the logger of ctx and then calls os.Exit(1)
+func CtxFatalf(ctx context.Context, format string, v ...interface{}) {
 	FromContext(ctx).Fatalf(format, v...)
 }
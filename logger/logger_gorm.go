@@ -3,6 +3,7 @@ package logger
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
@@ -12,31 +13,66 @@ import (
 type GormLogger struct {
 	logger.Interface
 	SlowThreshold time.Duration
+	// Level of gorm messages to log: Silent, Error, Warn or Info (SQL traces); zero means Info
+	LogLevel logger.LogLevel
+	// Do not report gorm.ErrRecordNotFound as an SQL error
+	IgnoreRecordNotFoundError bool
+	// Maximum length of the SQL written to the log, 0 means unlimited
+	MaxSQLLength int
+
+	stats *SQLStats
 }
 
 func NewGormLogger() *GormLogger {
 	return &GormLogger{
-		SlowThreshold: time.Second,
+		SlowThreshold:             time.Second,
+		LogLevel:                  logger.Info,
+		IgnoreRecordNotFoundError: true,
+		stats:                     NewSQLStats(defaultMaxFingerprints),
 	}
 }
 
+// LogMode returns a copy of the logger using level, metrics are shared with l
 func (l *GormLogger) LogMode(level logger.LogLevel) logger.Interface {
-	return l
+	newLogger := *l
+	newLogger.LogLevel = level
+	return &newLogger
+}
+
+func (l *GormLogger) level() logger.LogLevel {
+	if l.LogLevel == 0 {
+		return logger.Info
+	}
+	return l.LogLevel
+}
+
+// Stats returns the SQL metrics collected by the logger, shared by all its LogMode copies
+func (l *GormLogger) Stats() *SQLStats {
+	return l.stats
 }
 
 func (l *GormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level() < logger.Info {
+		return
+	}
 	log := FromContext(ctx)
 	log.Infof(msg, data...)
 }
 
 // Warn print warning
 func (l *GormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level() < logger.Warn {
+		return
+	}
 	log := FromContext(ctx)
 	log.Warnf(msg, data...)
 }
 
 // Error print error
 func (l *GormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level() < logger.Error {
+		return
+	}
 	log := FromContext(ctx)
 	log.Errorf(msg, data...)
 }
@@ -44,7 +80,30 @@ func (l *GormLogger) Error(ctx context.Context, msg string, data ...interface{})
 // Trace print sql and log level
 func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
 	elapsed := time.Since(begin)
+	isErr := err != nil && (!errors.Is(err, gorm.ErrRecordNotFound) || !l.IgnoreRecordNotFoundError)
+	isSlow := l.SlowThreshold > 0 && elapsed > l.SlowThreshold
+
+	var level logger.LogLevel
+	switch {
+	case isErr:
+		level = logger.Error
+	case isSlow:
+		level = logger.Warn
+	default:
+		level = logger.Info
+	}
+	if l.stats == nil && l.level() < level {
+		return
+	}
+
 	sql, rows := fc()
+	fingerprint := SQLFingerprint(sql)
+	if l.stats != nil {
+		l.stats.Observe(fingerprint, elapsed, isErr)
+	}
+	if l.level() < level {
+		return
+	}
 
 	// get traceID and userID
 	traceID := GetTraceID(ctx)
@@ -53,6 +112,10 @@ func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql
 	// get log instance
 	log := FromContext(ctx)
 
+	if l.MaxSQLLength > 0 && len(sql) > l.MaxSQLLength {
+		sql = fmt.Sprintf("%s... (%d bytes truncated)", sql[:l.MaxSQLLength], len(sql)-l.MaxSQLLength)
+	}
+
 	// build log fields
 	fields := []interface{}{
 		"elapsed", elapsed,
@@ -68,13 +131,15 @@ func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql
 		fields = append(fields, "user_id", userID)
 	}
 
-	switch {
-	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+	switch level {
+	case logger.Error:
 		fields = append(fields, "error", err)
 		log.Errorw("SQL Error", fields...)
-	case elapsed > l.SlowThreshold:
+	case logger.Warn:
+		fields = append(fields, "fingerprint", fingerprint)
 		log.Warnw("Slow SQL", fields...)
 	default:
-		log.Debugw("SQL Trace", fields...)
+		// written at info so db.Debug() shows up with the usual info level
+		log.Infow("SQL Trace", fields...)
 	}
 }
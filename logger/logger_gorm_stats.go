@@ -0,0 +1,290 @@
+package logger
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SQLLatencyBuckets are the upper bounds of the latency histogram kept per fingerprint,
+// a last implicit bucket counts queries slower than all of them
+var SQLLatencyBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+}
+
+const defaultMaxFingerprints = 1000
+
+// OtherFingerprint groups the queries seen after the fingerprint limit was reached
+const OtherFingerprint = "<other>"
+
+// SQLStat holds the metrics of one SQL fingerprint
+type SQLStat struct {
+	Fingerprint string
+	Count       int64
+	Errors      int64
+	Total       time.Duration
+	Max         time.Duration
+	// Buckets[i] counts queries not slower than SQLLatencyBuckets[i],
+	// the last element those slower than every bound
+	Buckets []int64
+}
+
+// Avg returns the mean latency of the fingerprint
+func (s SQLStat) Avg() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+// SQLStats aggregates query metrics per fingerprint
+type SQLStats struct {
+	mu    sync.Mutex
+	max   int
+	stats map[string]*SQLStat
+}
+
+// NewSQLStats creates SQLStats tracking at most maxFingerprints distinct fingerprints
+func NewSQLStats(maxFingerprints int) *SQLStats {
+	return &SQLStats{
+		max:   maxFingerprints,
+		stats: make(map[string]*SQLStat),
+	}
+}
+
+// Observe records one execution of the query identified by fingerprint
+func (s *SQLStats) Observe(fingerprint string, elapsed time.Duration, failed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, ok := s.stats[fingerprint]
+	if !ok {
+		if s.max > 0 && len(s.stats) >= s.max {
+			fingerprint = OtherFingerprint
+			st = s.stats[fingerprint]
+		}
+		if st == nil {
+			st = &SQLStat{Fingerprint: fingerprint, Buckets: make([]int64, len(SQLLatencyBuckets)+1)}
+			s.stats[fingerprint] = st
+		}
+	}
+
+	st.Count++
+	if failed {
+		st.Errors++
+	}
+	st.Total += elapsed
+	st.Max = max(st.Max, elapsed)
+	st.Buckets[sort.Search(len(SQLLatencyBuckets), func(i int) bool {
+		return elapsed <= SQLLatencyBuckets[i]
+	})]++
+}
+
+// Snapshot returns a copy of the metrics sorted by total time, slowest first
+func (s *SQLStats) Snapshot() []SQLStat {
+	s.mu.Lock()
+	stats := make([]SQLStat, 0, len(s.stats))
+	for _, st := range s.stats {
+		cp := *st
+		cp.Buckets = append([]int64(nil), st.Buckets...)
+		stats = append(stats, cp)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Total != stats[j].Total {
+			return stats[i].Total > stats[j].Total
+		}
+		return stats[i].Fingerprint < stats[j].Fingerprint
+	})
+	return stats
+}
+
+// Get returns the metrics of fingerprint
+func (s *SQLStats) Get(fingerprint string) (SQLStat, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.stats[fingerprint]
+	if !ok {
+		return SQLStat{}, false
+	}
+	cp := *st
+	cp.Buckets = append([]int64(nil), st.Buckets...)
+	return cp, true
+}
+
+// Reset forgets all collected metrics
+func (s *SQLStats) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats = make(map[string]*SQLStat)
+}
+
+// SQLFingerprint normalizes sql so that queries differing only by their literals
+// share a fingerprint: string and numeric literals become ?, IN and VALUES lists of them (?),
+// and whitespace is collapsed
+func SQLFingerprint(sql string) string {
+	var b strings.Builder
+	b.Grow(len(sql))
+
+	lastSpace := true
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == '\'' || c == '"':
+			// quoted literal, doubled quotes and backslashes escape the quote
+			j := i + 1
+			for j < len(sql) {
+				if sql[j] == '\\' {
+					j += 2
+					continue
+				}
+				if sql[j] == c {
+					if j+1 < len(sql) && sql[j+1] == c {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			if c == '"' {
+				// double quotes delimit identifiers in some dialects, keep them
+				b.WriteString(sql[i:min(j+1, len(sql))])
+			} else {
+				b.WriteByte('?')
+			}
+			i = j + 1
+			lastSpace = false
+		case isDigit(c) && (i == 0 || !isIdentByte(sql[i-1])),
+			c == '-' && i+1 < len(sql) && isDigit(sql[i+1]) && isOperandStart(b.String()):
+			j := i + 1
+			for j < len(sql) && (isDigit(sql[j]) || sql[j] == '.' || sql[j] == 'e' || sql[j] == 'E' || sql[j] == 'x' || isHexLetter(sql[j])) {
+				j++
+			}
+			b.WriteByte('?')
+			i = j
+			lastSpace = false
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if !lastSpace {
+				b.WriteByte(' ')
+			}
+			lastSpace = true
+			i++
+		default:
+			b.WriteByte(c)
+			lastSpace = false
+			i++
+		}
+	}
+	return collapseLists(strings.TrimSpace(b.String()))
+}
+
+// isOperandStart reports whether a '-' following out is a sign rather than a subtraction
+func isOperandStart(out string) bool {
+	out = strings.TrimRight(out, " ")
+	return out == "" || strings.ContainsRune("(,=<>", rune(out[len(out)-1]))
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isHexLetter(c byte) bool {
+	return (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// collapseLists turns placeholder lists following IN or VALUES, such as
+// "IN (?, ?, ?)" and "VALUES (?,?), (?,?)", into "(?)" so that they share a
+// fingerprint whatever their length; other lists like function arguments or
+// "LIMIT ?, ?" are left alone
+func collapseLists(s string) string {
+	if !strings.Contains(s, "(?") {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '(' {
+			if kw := listKeyword(b.String()); kw != "" {
+				if end := placeholderListEnd(s, i+1); end > 0 {
+					b.WriteString("(?)")
+					i = end
+					if kw == "VALUES" {
+						i = skipValueRows(s, i+1) - 1
+					}
+					continue
+				}
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// skipValueRows returns the index following the ", (?, ?)" rows starting at i
+func skipValueRows(s string, i int) int {
+	for {
+		j := i
+		for j < len(s) && s[j] == ' ' {
+			j++
+		}
+		if j >= len(s) || s[j] != ',' {
+			return i
+		}
+		j++
+		for j < len(s) && s[j] == ' ' {
+			j++
+		}
+		if j >= len(s) || s[j] != '(' {
+			return i
+		}
+		end := placeholderListEnd(s, j+1)
+		if end < 0 {
+			return i
+		}
+		i = end + 1
+	}
+}
+
+// listKeyword returns IN or VALUES when out ends with that keyword, or ""
+func listKeyword(out string) string {
+	out = strings.TrimRight(out, " ")
+	for _, kw := range []string{"IN", "VALUES"} {
+		if len(out) >= len(kw) && strings.EqualFold(out[len(out)-len(kw):], kw) &&
+			(len(out) == len(kw) || !isIdentByte(out[len(out)-len(kw)-1])) {
+			return kw
+		}
+	}
+	return ""
+}
+
+// placeholderListEnd returns the index of the ')' closing a list starting at i
+// made only of placeholders, or -1 when the group holds anything else
+func placeholderListEnd(s string, i int) int {
+	seen := false
+	for ; i < len(s); i++ {
+		switch s[i] {
+		case '?':
+			seen = true
+		case ',', ' ':
+		case ')':
+			if seen {
+				return i
+			}
+			return -1
+		default:
+			return -1
+		}
+	}
+	return -1
+}
@@ -0,0 +1,138 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func observeDefaultLogger(t *testing.T) *observer.ObservedLogs {
+	core, logs := observer.New(zapcore.DebugLevel)
+	old := DefaultLogger
+	DefaultLogger = &Logger{SugaredLogger: zap.New(core).Sugar()}
+	t.Cleanup(func() { DefaultLogger = old })
+	return logs
+}
+
+func TestSQLFingerprint(t *testing.T) {
+	cases := map[string]string{
+		"SELECT * FROM `users` WHERE id = 42 AND name = 'bob'":     "SELECT * FROM `users` WHERE id = ? AND name = ?",
+		"SELECT * FROM users WHERE id IN (1, 2, 3)":                "SELECT * FROM users WHERE id IN (?)",
+		"SELECT *\n  FROM t1   WHERE price > 3.14 LIMIT 10":        "SELECT * FROM t1 WHERE price > ? LIMIT ?",
+		"UPDATE t SET note = 'it''s', v = 0x1F WHERE \"col2\" = 7": "UPDATE t SET note = ?, v = ? WHERE \"col2\" = ?",
+		"INSERT INTO t (a,b) VALUES ('x\\'y',-5)":                  "INSERT INTO t (a,b) VALUES (?)",
+		"insert into t values (1, 2), (3, 4)":                      "insert into t values (?)",
+		"SELECT f(1), (2, 3) FROM t":                               "SELECT f(?), (?, ?) FROM t",
+		"SELECT GREATEST(1, 2) FROM t LIMIT 10, 20":                "SELECT GREATEST(?, ?) FROM t LIMIT ?, ?",
+		"SELECT * FROM t WHERE pin (1, 2) OR id IN (1, a)":         "SELECT * FROM t WHERE pin (?, ?) OR id IN (?, a)",
+	}
+	for sql, want := range cases {
+		assert.Equal(t, want, SQLFingerprint(sql), sql)
+	}
+
+	// bulk inserts of any size share one fingerprint
+	for _, sql := range []string{
+		"INSERT INTO t (a) VALUES (1)",
+		"INSERT INTO t (a) VALUES (1), (2)",
+		"INSERT INTO t (a) VALUES (1),(2),(3)",
+	} {
+		assert.Equal(t, "INSERT INTO t (a) VALUES (?)", SQLFingerprint(sql), sql)
+	}
+	for _, sql := range []string{
+		"INSERT INTO t (a, b) VALUES (1, 'x')",
+		"INSERT INTO t (a, b) VALUES (1, 'x'), (2, 'y')",
+		"INSERT INTO t (a, b) VALUES (1, 'x'), (2, 'y'), (3, 'z')",
+	} {
+		assert.Equal(t, "INSERT INTO t (a, b) VALUES (?)", SQLFingerprint(sql), sql)
+	}
+	assert.Equal(t, "INSERT INTO t (a) VALUES (?) ON CONFLICT DO NOTHING",
+		SQLFingerprint("INSERT INTO t (a) VALUES (1), (2) ON CONFLICT DO NOTHING"))
+}
+
+func TestSQLStats(t *testing.T) {
+	stats := NewSQLStats(2)
+	stats.Observe("a", 2*time.Millisecond, false)
+	stats.Observe("a", 2*time.Second, true)
+	stats.Observe("b", time.Millisecond, false)
+	stats.Observe("c", time.Millisecond, false)
+	stats.Observe("d", time.Hour, false)
+
+	a, ok := stats.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), a.Count)
+	assert.Equal(t, int64(1), a.Errors)
+	assert.Equal(t, 2*time.Second, a.Max)
+	assert.Equal(t, time.Second+time.Millisecond, a.Avg())
+	assert.Equal(t, []int64{0, 1, 0, 0, 0, 0, 0, 1, 0}, a.Buckets)
+
+	other, ok := stats.Get(OtherFingerprint)
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), other.Count)
+	assert.Equal(t, int64(1), other.Buckets[len(SQLLatencyBuckets)])
+
+	snapshot := stats.Snapshot()
+	assert.Equal(t, OtherFingerprint, snapshot[0].Fingerprint)
+	assert.Equal(t, "a", snapshot[1].Fingerprint)
+
+	stats.Reset()
+	assert.Empty(t, stats.Snapshot())
+}
+
+func TestGormLoggerLevels(t *testing.T) {
+	logs := observeDefaultLogger(t)
+	l := NewGormLogger()
+	l.SlowThreshold = 100 * time.Millisecond
+	l.MaxSQLLength = 10
+	ctx := WithTraceID(context.Background(), "t-1")
+	sql := func(q string) func() (string, int64) {
+		return func() (string, int64) { return q, 1 }
+	}
+
+	l.Trace(ctx, time.Now(), sql("SELECT 1"), nil)
+	l.Trace(ctx, time.Now().Add(-time.Second), sql("SELECT * FROM orders WHERE id = 7"), nil)
+	l.Trace(ctx, time.Now(), sql("SELECT 2"), gorm.ErrRecordNotFound)
+	l.Trace(ctx, time.Now(), sql("SELECT 3"), errors.New("boom"))
+
+	entries := logs.TakeAll()
+	assert.Len(t, entries, 4)
+	assert.Equal(t, "SQL Trace", entries[0].Message)
+	assert.Equal(t, zapcore.InfoLevel, entries[0].Level)
+	assert.Equal(t, "Slow SQL", entries[1].Message)
+	slow := entries[1].ContextMap()
+	assert.Equal(t, "SELECT * F... (23 bytes truncated)", slow["sql"])
+	assert.Equal(t, "SELECT * FROM orders WHERE id = ?", slow["fingerprint"])
+	assert.Equal(t, "t-1", slow["trace_id"])
+	assert.Equal(t, "SQL Trace", entries[2].Message)
+	assert.Equal(t, "SQL Error", entries[3].Message)
+
+	// Warn drops traces, Silent drops everything but metrics are still collected
+	warn := l.LogMode(gormlogger.Warn)
+	warn.Trace(ctx, time.Now(), sql("SELECT 1"), nil)
+	warn.Info(ctx, "hidden")
+	warn.Warn(ctx, "shown %d", 1)
+	l.LogMode(gormlogger.Silent).Trace(ctx, time.Now(), sql("SELECT 1"), errors.New("boom"))
+	entries = logs.TakeAll()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "shown 1", entries[0].Message)
+
+	st, ok := l.Stats().Get("SELECT ?")
+	assert.True(t, ok)
+	assert.Equal(t, int64(5), st.Count)
+	assert.Equal(t, int64(2), st.Errors)
+
+	// record not found is an error once no longer ignored
+	l.IgnoreRecordNotFoundError = false
+	l.Trace(ctx, time.Now(), sql("SELECT 2"), gorm.ErrRecordNotFound)
+	entries = logs.TakeAll()
+	assert.Len(t, entries, 1)
+	assert.True(t, strings.HasPrefix(entries[0].Message, "SQL Error"))
+}
@@ -0,0 +1,330 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HTTPSinkConfig holds the settings of an http output posting gzipped batches of entries
+type HTTPSinkConfig struct {
+	// Endpoint receiving POST requests with newline-delimited entries
+	URL string
+	// Extra request headers, e.g. Authorization
+	Headers map[string]string
+	// Batch size in bytes before compression that triggers a send, defaults to 1MB
+	BatchBytes int
+	// Maximum time an entry waits in the batch, defaults to 5s
+	BatchInterval time.Duration
+	// Number of retries of a failed send, defaults to 3
+	MaxRetries int
+	// Delay before the first retry, doubled on each further one; defaults to 500ms
+	RetryBackoff time.Duration
+	// Timeout of one request, defaults to 10s
+	Timeout time.Duration
+	// Number of batches waiting to be sent before new ones are spilled, defaults to 16
+	QueueSize int
+	// Directory batches are spilled to when they cannot be sent, empty drops them;
+	// spilled batches are resent after the next successful send
+	SpillDir string
+	// Total size of spilled batches kept in SpillDir, the oldest ones are deleted
+	// beyond it; defaults to 100MB
+	MaxSpillBytes int64
+	// Number of spilled batches kept in SpillDir, the oldest ones are deleted
+	// beyond it; zero keeps any number
+	MaxSpillFiles int
+}
+
+// httpSink buffers entries and ships them in batches from a background goroutine
+type httpSink struct {
+	cfg    HTTPSinkConfig
+	client *http.Client
+
+	mu  sync.Mutex
+	buf bytes.Buffer
+
+	batches chan []byte
+	done    chan struct{}
+	wg      sync.WaitGroup
+	once    sync.Once
+
+	// serializes resending of spilled batches
+	spillMu  sync.Mutex
+	spillSeq atomic.Uint64
+}
+
+func newHTTPSink(cfg *HTTPSinkConfig) (*httpSink, error) {
+	if cfg == nil || cfg.URL == "" {
+		return nil, errors.New("logger: http output requires a url")
+	}
+
+	c := *cfg
+	if c.BatchBytes <= 0 {
+		c.BatchBytes = megabyte
+	}
+	if c.BatchInterval <= 0 {
+		c.BatchInterval = 5 * time.Second
+	}
+	if c.MaxRetries < 0 {
+		c.MaxRetries = 0
+	} else if c.MaxRetries == 0 {
+		c.MaxRetries = 3
+	}
+	if c.RetryBackoff <= 0 {
+		c.RetryBackoff = 500 * time.Millisecond
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = 16
+	}
+	if c.MaxSpillBytes <= 0 {
+		c.MaxSpillBytes = 100 * megabyte
+	}
+	if c.SpillDir != "" {
+		if err := os.MkdirAll(c.SpillDir, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &httpSink{
+		cfg:     c,
+		client:  &http.Client{Timeout: c.Timeout},
+		batches: make(chan []byte, c.QueueSize),
+		done:    make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.run()
+	return s, nil
+}
+
+func (s *httpSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	select {
+	case <-s.done:
+		s.mu.Unlock()
+		return 0, os.ErrClosed
+	default:
+	}
+	s.buf.Write(p)
+	var batch []byte
+	if s.buf.Len() >= s.cfg.BatchBytes {
+		batch = s.take()
+	}
+	s.mu.Unlock()
+
+	if batch != nil {
+		s.enqueue(batch)
+	}
+	return len(p), nil
+}
+
+// take empties the buffer and returns its content, s.mu must be held
+func (s *httpSink) take() []byte {
+	if s.buf.Len() == 0 {
+		return nil
+	}
+	batch := bytes.Clone(s.buf.Bytes())
+	s.buf.Reset()
+	return batch
+}
+
+func (s *httpSink) flushBuffer() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.take()
+}
+
+// enqueue hands batch to the sender, spilling it when the queue is full
+func (s *httpSink) enqueue(batch []byte) {
+	select {
+	case s.batches <- batch:
+	default:
+		s.spill(batch)
+	}
+}
+
+func (s *httpSink) run() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.cfg.BatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case batch := <-s.batches:
+			s.send(batch)
+		case <-ticker.C:
+			if batch := s.flushBuffer(); batch != nil {
+				s.send(batch)
+			}
+		case <-s.done:
+			for {
+				select {
+				case batch := <-s.batches:
+					s.send(batch)
+				default:
+					if batch := s.flushBuffer(); batch != nil {
+						s.send(batch)
+					}
+					return
+				}
+			}
+		}
+	}
+}
+
+// send posts batch with retries, spilling it when every attempt failed
+func (s *httpSink) send(batch []byte) {
+	backoff := s.cfg.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := s.post(batch)
+		if err == nil {
+			s.resendSpilled()
+			return
+		}
+		if attempt >= s.cfg.MaxRetries {
+			fmt.Fprintln(os.Stderr, "logger: http sink send failed:", err)
+			s.spill(batch)
+			return
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-s.done:
+			// shutting down, do not keep retrying
+			s.spill(batch)
+			return
+		}
+		backoff *= 2
+	}
+}
+
+func (s *httpSink) post(batch []byte) error {
+	var body bytes.Buffer
+	gz := gzip.NewWriter(&body)
+	if _, err := gz.Write(batch); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.cfg.URL, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("Content-Encoding", "gzip")
+	for k, v := range s.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("logger: http sink got status %s", resp.Status)
+	}
+	return nil
+}
+
+// spill stores batch in SpillDir so that it can be resent later
+func (s *httpSink) spill(batch []byte) {
+	if s.cfg.SpillDir == "" {
+		fmt.Fprintf(os.Stderr, "logger: http sink dropped a batch of %d bytes\n", len(batch))
+		return
+	}
+	name := strconv.FormatInt(time.Now().UnixNano(), 10) + "-" + strconv.FormatUint(s.spillSeq.Add(1), 10) + ".ndjson"
+	tmp := filepath.Join(s.cfg.SpillDir, name+".tmp")
+	if err := os.WriteFile(tmp, batch, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, "logger: http sink spill failed:", err)
+		return
+	}
+	_ = os.Rename(tmp, filepath.Join(s.cfg.SpillDir, name))
+	s.trimSpilled()
+}
+
+// trimSpilled deletes the oldest spilled batches until SpillDir is within
+// MaxSpillBytes and MaxSpillFiles
+func (s *httpSink) trimSpilled() {
+	files, _ := filepath.Glob(filepath.Join(s.cfg.SpillDir, "*.ndjson"))
+	sort.Strings(files)
+	sizes := make([]int64, len(files))
+	var total int64
+	for i, f := range files {
+		if fi, err := os.Stat(f); err == nil {
+			sizes[i] = fi.Size()
+			total += sizes[i]
+		}
+	}
+
+	count := len(files)
+	for i, f := range files {
+		if total <= s.cfg.MaxSpillBytes && (s.cfg.MaxSpillFiles <= 0 || count <= s.cfg.MaxSpillFiles) {
+			return
+		}
+		if err := os.Remove(f); err != nil {
+			// already resent or removed by a concurrent trim
+			continue
+		}
+		total -= sizes[i]
+		count--
+		fmt.Fprintf(os.Stderr, "logger: http sink spill is full, dropped %s (%d bytes)\n", filepath.Base(f), sizes[i])
+	}
+}
+
+// resendSpilled posts spilled batches oldest first, stopping at the first failure
+func (s *httpSink) resendSpilled() {
+	if s.cfg.SpillDir == "" {
+		return
+	}
+	s.spillMu.Lock()
+	defer s.spillMu.Unlock()
+
+	files, _ := filepath.Glob(filepath.Join(s.cfg.SpillDir, "*.ndjson"))
+	sort.Strings(files)
+	for _, f := range files {
+		batch, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		if err := s.post(batch); err != nil {
+			return
+		}
+		_ = os.Remove(f)
+	}
+}
+
+// Sync sends the buffered entries right away
+func (s *httpSink) Sync() error {
+	if batch := s.flushBuffer(); batch != nil {
+		if err := s.post(batch); err != nil {
+			s.spill(batch)
+			return err
+		}
+	}
+	return nil
+}
+
+// Close sends everything still buffered or queued and stops the sender
+func (s *httpSink) Close() error {
+	s.once.Do(func() {
+		close(s.done)
+		s.wg.Wait()
+	})
+	return nil
+}
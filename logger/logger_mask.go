@@ -0,0 +1,434 @@
+package logger
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Jsharkc/mygopkg/stringutil"
+	"go.uber.org/zap/zapcore"
+)
+
+// MaskedValue replaces the value of fields whose key is listed in MaskConfig.Keys
+const MaskedValue = "******"
+
+// Built-in pattern names accepted by MaskConfig.Patterns
+const (
+	MaskPhone    = "phone"
+	MaskEmail    = "email"
+	MaskIDCard   = "idcard"
+	MaskBankCard = "bankcard"
+)
+
+// MaskConfig defines which sensitive data is redacted before entries are encoded
+type MaskConfig struct {
+	// Field keys whose values are replaced entirely, matched case-insensitively
+	Keys []string
+	// Built-in patterns masked inside messages and string values
+	Patterns []string
+	// Custom rules applied after the built-in patterns
+	Rules []MaskRule
+}
+
+// MaskRule replaces every match of Pattern with Replacement
+type MaskRule struct {
+	// Regular expression to look for
+	Pattern string
+	// Replacement text, may reference groups like ${1}; empty means MaskedValue
+	Replacement string
+}
+
+// DefaultMaskConfig masks common credential keys and all built-in patterns
+func DefaultMaskConfig() MaskConfig {
+	return MaskConfig{
+		Keys:     []string{"password", "passwd", "token", "secret", "authorization"},
+		Patterns: []string{MaskIDCard, MaskBankCard, MaskPhone, MaskEmail},
+	}
+}
+
+type maskPattern struct {
+	re *regexp.Regexp
+	// quick check that must pass before the regexp is run
+	candidate func(s string) bool
+	// replace returns the masked form of a match
+	replace func(match string) string
+	// digitBounded rejects matches that are part of a longer run of digits
+	digitBounded bool
+}
+
+// Masker redacts sensitive data in strings and log fields
+type Masker struct {
+	keys     map[string]struct{}
+	patterns []maskPattern
+}
+
+// NewMasker compiles cfg into a Masker
+func NewMasker(cfg MaskConfig) (*Masker, error) {
+	m := &Masker{keys: make(map[string]struct{}, len(cfg.Keys))}
+	for _, key := range cfg.Keys {
+		m.keys[strings.ToLower(key)] = struct{}{}
+	}
+
+	for _, name := range cfg.Patterns {
+		p, err := builtinMaskPattern(name)
+		if err != nil {
+			return nil, err
+		}
+		m.patterns = append(m.patterns, p)
+	}
+
+	for _, rule := range cfg.Rules {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("logger: invalid mask rule %q: %w", rule.Pattern, err)
+		}
+		replacement := rule.Replacement
+		if replacement == "" {
+			replacement = MaskedValue
+		}
+		m.patterns = append(m.patterns, maskPattern{
+			re: re,
+			replace: func(match string) string {
+				return re.ReplaceAllString(match, replacement)
+			},
+		})
+	}
+	return m, nil
+}
+
+func builtinMaskPattern(name string) (maskPattern, error) {
+	switch name {
+	case MaskPhone:
+		return maskPattern{
+			re:           regexp.MustCompile(stringutil.PatternPhonePart),
+			candidate:    func(s string) bool { return hasDigitRun(s, 11) },
+			replace:      func(m string) string { return keepEnds(m, len(m)-8, 4) },
+			digitBounded: true,
+		}, nil
+	case MaskEmail:
+		return maskPattern{
+			re:        regexp.MustCompile(stringutil.PatternEmailPart),
+			candidate: func(s string) bool { return strings.IndexByte(s, '@') != -1 },
+			replace: func(m string) string {
+				at := strings.IndexByte(m, '@')
+				return m[:1] + "***" + m[at:]
+			},
+		}, nil
+	case MaskIDCard:
+		return maskPattern{
+			re:           regexp.MustCompile(stringutil.PatternIDCardPart),
+			candidate:    func(s string) bool { return hasDigitRun(s, 17) },
+			replace:      func(m string) string { return keepEnds(m, 6, 4) },
+			digitBounded: true,
+		}, nil
+	case MaskBankCard:
+		return maskPattern{
+			re:           regexp.MustCompile(stringutil.PatternBankCardPart),
+			candidate:    func(s string) bool { return hasDigitRun(s, 13) },
+			replace:      func(m string) string { return keepEnds(m, 6, 4) },
+			digitBounded: true,
+		}, nil
+	}
+	return maskPattern{}, fmt.Errorf("logger: unknown mask pattern %q", name)
+}
+
+// hasDigitRun reports whether s contains at least n consecutive ASCII digits
+func hasDigitRun(s string, n int) bool {
+	run := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			run++
+			if run >= n {
+				return true
+			}
+		} else {
+			run = 0
+		}
+	}
+	return false
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// keepEnds keeps the first head and last tail characters of s, masking the rest with '*'
+func keepEnds(s string, head, tail int) string {
+	if head < 0 {
+		head = 0
+	}
+	if head+tail >= len(s) {
+		return strings.Repeat("*", len(s))
+	}
+	return s[:head] + strings.Repeat("*", len(s)-head-tail) + s[len(s)-tail:]
+}
+
+// MaskString returns s with every configured pattern masked,
+// s itself is returned without allocation when nothing matches
+func (m *Masker) MaskString(s string) string {
+	for _, p := range m.patterns {
+		if p.candidate != nil && !p.candidate(s) {
+			continue
+		}
+		s = p.apply(s)
+	}
+	return s
+}
+
+func (p maskPattern) apply(s string) string {
+	locs := p.re.FindAllStringIndex(s, -1)
+	if len(locs) == 0 {
+		return s
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range locs {
+		start, end := loc[0], loc[1]
+		if p.digitBounded && ((start > 0 && isDigit(s[start-1])) || (end < len(s) && isDigit(s[end]))) {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(p.replace(s[start:end]))
+		last = end
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// IsSensitiveKey reports whether the value stored under key must be hidden
+func (m *Masker) IsSensitiveKey(key string) bool {
+	if len(m.keys) == 0 {
+		return false
+	}
+	_, ok := m.keys[strings.ToLower(key)]
+	return ok
+}
+
+// maxMaskDepth bounds the walk of nested values, guarding against pointer cycles
+const maxMaskDepth = 32
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// MaskValue masks strings and the contents of maps, slices and structs built from them,
+// struct fields are matched against the sensitive keys by name and json tag;
+// v itself is returned when nothing is masked
+func (m *Masker) MaskValue(v any) any {
+	if masked, ok := m.maskValue(reflect.ValueOf(v), 0); ok {
+		return masked
+	}
+	return v
+}
+
+// maskValue returns the masked form of rv and whether anything was masked,
+// maps and structs are returned as map[string]any keyed like their JSON encoding
+func (m *Masker) maskValue(rv reflect.Value, depth int) (any, bool) {
+	if !rv.IsValid() || depth > maxMaskDepth {
+		return nil, false
+	}
+	// values with their own encoding, like time.Time, are kept as they are
+	if rv.Kind() != reflect.Ptr && rv.Kind() != reflect.Interface &&
+		(rv.Type().Implements(jsonMarshalerType) || rv.Type().Implements(textMarshalerType)) {
+		return nil, false
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		s := rv.String()
+		masked := m.MaskString(s)
+		return masked, masked != s
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return nil, false
+		}
+		return m.maskValue(rv.Elem(), depth+1)
+	case reflect.Map:
+		if rv.IsNil() {
+			return nil, false
+		}
+		out := make(map[string]any, rv.Len())
+		changed := false
+		iter := rv.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			changed = m.maskEntry(out, key, key, iter.Value(), depth) || changed
+		}
+		return out, changed
+	case reflect.Slice, reflect.Array:
+		// byte slices are binary data
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return nil, false
+		}
+		out := make([]any, rv.Len())
+		changed := false
+		for i := range out {
+			item := rv.Index(i)
+			if masked, ok := m.maskValue(item, depth+1); ok {
+				out[i], changed = masked, true
+			} else if item.CanInterface() {
+				out[i] = item.Interface()
+			}
+		}
+		return out, changed
+	case reflect.Struct:
+		out := make(map[string]any, rv.NumField())
+		return out, m.maskStruct(rv, out, depth)
+	}
+	return nil, false
+}
+
+// maskStruct stores the exported fields of rv in out under their JSON names,
+// fields of embedded structs without a json name are promoted like encoding/json does
+func (m *Masker) maskStruct(rv reflect.Value, out map[string]any, depth int) bool {
+	changed := false
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		fv := rv.Field(i)
+		if field.Anonymous && name == "" {
+			if ev := reflect.Indirect(fv); ev.Kind() == reflect.Struct {
+				changed = m.maskStruct(ev, out, depth+1) || changed
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if strings.Contains(","+opts+",", ",omitempty,") && fv.IsZero() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		changed = m.maskEntry(out, name, field.Name, fv, depth) || changed
+	}
+	return changed
+}
+
+// maskEntry stores the masked form of v in out under key, v is replaced
+// entirely when key or alias is a sensitive key
+func (m *Masker) maskEntry(out map[string]any, key, alias string, v reflect.Value, depth int) bool {
+	if m.IsSensitiveKey(key) || m.IsSensitiveKey(alias) {
+		out[key] = MaskedValue
+		return true
+	}
+	if masked, ok := m.maskValue(v, depth+1); ok {
+		out[key] = masked
+		return true
+	}
+	if v.CanInterface() {
+		out[key] = v.Interface()
+	}
+	return false
+}
+
+// MaskField returns field with its value redacted according to the configuration
+func (m *Masker) MaskField(field zapcore.Field) zapcore.Field {
+	masked, _ := m.maskField(field)
+	return masked
+}
+
+// maskField is MaskField also reporting whether the field changed
+func (m *Masker) maskField(field zapcore.Field) (zapcore.Field, bool) {
+	if m.IsSensitiveKey(field.Key) {
+		return zapcore.Field{Key: field.Key, Type: zapcore.StringType, String: MaskedValue}, true
+	}
+	switch field.Type {
+	case zapcore.StringType:
+		if s := m.MaskString(field.String); s != field.String {
+			field.String = s
+			return field, true
+		}
+	case zapcore.ReflectType:
+		if v, ok := m.maskValue(reflect.ValueOf(field.Interface), 0); ok {
+			field.Interface = v
+			return field, true
+		}
+	case zapcore.ByteStringType, zapcore.BinaryType:
+		if b, ok := field.Interface.([]byte); ok {
+			if s := m.MaskString(string(b)); s != string(b) {
+				field.Interface = []byte(s)
+				return field, true
+			}
+		}
+	case zapcore.StringerType:
+		if v, ok := field.Interface.(fmt.Stringer); ok {
+			if s := v.String(); m.MaskString(s) != s {
+				return zapcore.Field{Key: field.Key, Type: zapcore.StringType, String: m.MaskString(s)}, true
+			}
+		}
+	case zapcore.ErrorType:
+		if err, ok := field.Interface.(error); ok {
+			if s := err.Error(); m.MaskString(s) != s {
+				return zapcore.Field{Key: field.Key, Type: zapcore.StringType, String: m.MaskString(s)}, true
+			}
+		}
+	}
+	return field, false
+}
+
+// maskFields masks fields, returning the slice itself when no field changes
+func (m *Masker) maskFields(fields []zapcore.Field) []zapcore.Field {
+	var masked []zapcore.Field
+	for i, f := range fields {
+		mf, changed := m.maskField(f)
+		if !changed {
+			continue
+		}
+		if masked == nil {
+			masked = slices.Clone(fields)
+		}
+		masked[i] = mf
+	}
+	if masked == nil {
+		return fields
+	}
+	return masked
+}
+
+// maskCore redacts messages and fields before handing entries to the wrapped core
+type maskCore struct {
+	zapcore.Core
+	masker *Masker
+}
+
+func newMaskCore(core zapcore.Core, masker *Masker) zapcore.Core {
+	if masker == nil {
+		return core
+	}
+	return &maskCore{Core: core, masker: masker}
+}
+
+func (c *maskCore) With(fields []zapcore.Field) zapcore.Core {
+	return &maskCore{
+		Core:   c.Core.With(c.masker.maskFields(fields)),
+		masker: c.masker,
+	}
+}
+
+func (c *maskCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *maskCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = c.masker.MaskString(ent.Message)
+	return c.Core.Write(ent, c.masker.maskFields(fields))
+}
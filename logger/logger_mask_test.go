@@ -0,0 +1,158 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestMaskerMaskString(t *testing.T) {
+	m, err := NewMasker(DefaultMaskConfig())
+	assert.NoError(t, err)
+
+	cases := map[string]string{
+		"call 13812341234 now":           "call 138****1234 now",
+		"mail alice@example.com please":  "mail a***@example.com please",
+		"id 110101199003071234":          "id 110101********1234",
+		"card 6222021234567890123 used":  "card 622202*********0123 used",
+		"order 202610181234567890123456": "order 202610181234567890123456",
+		"nothing sensitive":              "nothing sensitive",
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, m.MaskString(in), in)
+	}
+}
+
+func TestMaskerRules(t *testing.T) {
+	m, err := NewMasker(MaskConfig{
+		Rules: []MaskRule{
+			{Pattern: `sk-[a-zA-Z0-9]+`},
+			{Pattern: `(ip=)\d+\.\d+\.\d+\.\d+`, Replacement: "${1}x.x.x.x"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "key ****** from ip=x.x.x.x", m.MaskString("key sk-abc123 from ip=10.0.0.1"))
+
+	_, err = NewMasker(MaskConfig{Rules: []MaskRule{{Pattern: "("}}})
+	assert.Error(t, err)
+	_, err = NewMasker(MaskConfig{Patterns: []string{"unknown"}})
+	assert.Error(t, err)
+}
+
+func TestMaskCore(t *testing.T) {
+	m, err := NewMasker(DefaultMaskConfig())
+	assert.NoError(t, err)
+
+	obs, logs := observer.New(zapcore.InfoLevel)
+	log := zap.New(newMaskCore(obs, m)).With(zap.String("Token", "abc"))
+	log.Info("login 13812341234",
+		zap.String("password", "123456"),
+		zap.String("email", "bob@example.com"),
+		zap.Any("payload", map[string]any{"secret": "x", "phone": "13812341234"}),
+		zap.Error(errors.New("user bob@example.com not found")),
+	)
+	log.Debug("filtered by level")
+
+	entries := logs.TakeAll()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "login 138****1234", entries[0].Message)
+	ctx := entries[0].ContextMap()
+	assert.Equal(t, MaskedValue, ctx["Token"])
+	assert.Equal(t, MaskedValue, ctx["password"])
+	assert.Equal(t, "b***@example.com", ctx["email"])
+	assert.Equal(t, map[string]any{"secret": MaskedValue, "phone": "138****1234"}, ctx["payload"])
+	assert.Equal(t, "user b***@example.com not found", ctx["error"])
+}
+
+var errDiskFull = errors.New("disk full")
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errDiskFull }
+func (failingWriter) Sync() error               { return nil }
+
+func TestMaskCoreWriteError(t *testing.T) {
+	m, err := NewMasker(DefaultMaskConfig())
+	assert.NoError(t, err)
+
+	inner := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), failingWriter{}, zapcore.InfoLevel)
+	core := newMaskCore(inner, m)
+	err = core.Write(zapcore.Entry{Level: zapcore.InfoLevel, Message: "hello"}, nil)
+	assert.ErrorIs(t, err, errDiskFull)
+
+	// entries the wrapped core does not accept are not written at all
+	assert.Nil(t, core.Check(zapcore.Entry{Level: zapcore.DebugLevel, Message: "hello"}, nil))
+}
+
+type maskAddress struct {
+	City  string
+	Phone string `json:"phone"`
+}
+
+type maskPayload struct {
+	maskAddress
+	Name     string
+	Password string `json:"pwd"`
+	Email    string `json:"email,omitempty"`
+	Note     string `json:"-"`
+	Contacts []maskAddress
+	private  string
+}
+
+func TestMaskStructPayload(t *testing.T) {
+	m, err := NewMasker(DefaultMaskConfig())
+	assert.NoError(t, err)
+
+	obs, logs := observer.New(zapcore.InfoLevel)
+	log := zap.New(newMaskCore(obs, m)).Sugar()
+	payload := maskPayload{
+		maskAddress: maskAddress{City: "hz", Phone: "13812341234"},
+		Name:        "bob",
+		Password:    "hunter2",
+		Note:        "13812341234",
+		Contacts:    []maskAddress{{Phone: "13912341234"}},
+		private:     "x",
+	}
+	log.With("payload", payload).Info("x")
+	log.Infow("x", "payload", &payload)
+
+	want := map[string]any{
+		"City":     "hz",
+		"phone":    "138****1234",
+		"Name":     "bob",
+		"pwd":      MaskedValue,
+		"Contacts": []any{map[string]any{"City": "", "phone": "139****1234"}},
+	}
+	entries := logs.TakeAll()
+	assert.Len(t, entries, 2)
+	for _, e := range entries {
+		assert.Equal(t, want, e.ContextMap()["payload"])
+	}
+
+	// values without anything to mask are kept as they are
+	plain := maskAddress{City: "hz"}
+	assert.Equal(t, plain, m.MaskValue(plain))
+	now := time.Now()
+	assert.Equal(t, now, m.MaskValue(now))
+}
+
+func TestMaskBytesAndFields(t *testing.T) {
+	m, err := NewMasker(DefaultMaskConfig())
+	assert.NoError(t, err)
+
+	f := m.MaskField(zap.ByteString("body", []byte("call 13812341234")))
+	assert.Equal(t, []byte("call 138****1234"), f.Interface)
+	f = m.MaskField(zap.Binary("raw", []byte("bob@example.com")))
+	assert.Equal(t, []byte("b***@example.com"), f.Interface)
+
+	// the slice is reused when no field changes
+	fields := []zapcore.Field{zap.String("a", "plain"), zap.Int("n", 1)}
+	assert.Same(t, &fields[0], &m.maskFields(fields)[0])
+	masked := m.maskFields([]zapcore.Field{zap.String("a", "plain"), zap.String("token", "x")})
+	assert.Equal(t, MaskedValue, masked[1].String)
+}
@@ -0,0 +1,153 @@
+package logger
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// TCPSinkConfig holds the settings of a tcp output shipping newline-delimited entries
+type TCPSinkConfig struct {
+	// Address of the collector, e.g. 127.0.0.1:5170
+	Address string
+	// Timeout of a single dial attempt, defaults to 5s
+	DialTimeout time.Duration
+	// Timeout of writing one entry, the connection is dropped when it expires; defaults to 1s
+	WriteTimeout time.Duration
+	// Delay before the first reconnect attempt, doubled after every failure; defaults to 100ms
+	MinBackoff time.Duration
+	// Upper bound of the reconnect delay, defaults to 30s
+	MaxBackoff time.Duration
+}
+
+// errSinkBackoff is returned by writes dropped while waiting to reconnect
+var errSinkBackoff = errors.New("logger: sink disconnected, waiting to reconnect")
+
+// reconnectConn is a net.Conn writer that redials with exponential backoff in the
+// background, entries written while disconnected are dropped and reported as errors
+type reconnectConn struct {
+	network      string
+	address      string
+	dialTimeout  time.Duration
+	writeTimeout time.Duration
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+
+	mu       sync.Mutex
+	conn     net.Conn
+	dialing  bool
+	backoff  time.Duration
+	nextDial time.Time
+	closed   bool
+}
+
+// newReconnectConn dials address once before returning, later dials run in the background
+func newReconnectConn(network, address string, dialTimeout, writeTimeout, minBackoff, maxBackoff time.Duration) *reconnectConn {
+	if dialTimeout <= 0 {
+		dialTimeout = 5 * time.Second
+	}
+	if writeTimeout <= 0 {
+		writeTimeout = time.Second
+	}
+	if minBackoff <= 0 {
+		minBackoff = 100 * time.Millisecond
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = 30 * time.Second
+	}
+	c := &reconnectConn{
+		network:      network,
+		address:      address,
+		dialTimeout:  dialTimeout,
+		writeTimeout: writeTimeout,
+		minBackoff:   minBackoff,
+		maxBackoff:   maxBackoff,
+		dialing:      true,
+	}
+	c.dial()
+	return c
+}
+
+// redial starts a background dial unless one is running or a previous failure
+// asked to back off, c.mu must be held
+func (c *reconnectConn) redial() {
+	if c.dialing || time.Now().Before(c.nextDial) {
+		return
+	}
+	c.dialing = true
+	go c.dial()
+}
+
+// dial connects without holding c.mu, so writers never wait for it
+func (c *reconnectConn) dial() {
+	conn, err := net.DialTimeout(c.network, c.address, c.dialTimeout)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dialing = false
+	if err != nil {
+		if c.backoff == 0 {
+			c.backoff = c.minBackoff
+		} else {
+			c.backoff = min(c.backoff*2, c.maxBackoff)
+		}
+		c.nextDial = time.Now().Add(c.backoff)
+		return
+	}
+	if c.closed {
+		_ = conn.Close()
+		return
+	}
+	c.conn = conn
+	c.backoff = 0
+}
+
+// Write sends p on the current connection; a write that fails or exceeds the
+// write timeout drops the connection, which is then dialed again in the background
+func (c *reconnectConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return 0, net.ErrClosed
+	}
+	if c.conn == nil {
+		c.redial()
+		return 0, errSinkBackoff
+	}
+	_ = c.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+	n, err := c.conn.Write(p)
+	if err != nil {
+		c.reset()
+		c.redial()
+	}
+	return n, err
+}
+
+// reset drops the current connection, c.mu must be held
+func (c *reconnectConn) reset() {
+	if c.conn != nil {
+		_ = c.conn.Close()
+		c.conn = nil
+	}
+}
+
+func (c *reconnectConn) Sync() error {
+	return nil
+}
+
+func (c *reconnectConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	c.reset()
+	return nil
+}
+
+func newTCPSink(cfg *TCPSinkConfig) (*reconnectConn, error) {
+	if cfg == nil || cfg.Address == "" {
+		return nil, errors.New("logger: tcp output requires an address")
+	}
+	return newReconnectConn("tcp", cfg.Address, cfg.DialTimeout, cfg.WriteTimeout, cfg.MinBackoff, cfg.MaxBackoff), nil
+}
@@ -0,0 +1,315 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Output destination types
+const (
+	OutputFile   = "file"
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
+	OutputSyslog = "syslog"
+	OutputTCP    = "tcp"
+	OutputHTTP   = "http"
+)
+
+// Encoder names accepted by OutputConfig.Encoder
+const (
+	EncoderFormatter   = "formatter"
+	EncoderJSON        = "json"
+	EncoderConsole     = "console"
+	EncoderDevelopment = "development"
+)
+
+// OutputConfig describes one destination the logger writes to
+type OutputConfig struct {
+	// Destination type: file, stdout, stderr, syslog, tcp or http
+	Type string
+	// File name, relative paths are resolved against LogConfig.FileDir; defaults to AppName.log
+	Filename string
+	// Lowest level written to this output, empty means LogConfig.Level
+	MinLevel string
+	// Highest level written to this output, empty means no upper bound
+	MaxLevel string
+	// Encoder: formatter, json, console or development; defaults to json for
+	// tcp and http outputs and to formatter for the others
+	Encoder string
+	// Rotation settings of file outputs, nil inherits those of LogConfig
+	Rotation *RotationConfig
+	// Settings of syslog outputs
+	Syslog *SyslogConfig
+	// Settings of tcp outputs
+	TCP *TCPSinkConfig
+	// Settings of http outputs
+	HTTP *HTTPSinkConfig
+}
+
+// RotationConfig holds the rotation settings of a file output
+type RotationConfig struct {
+	// Maximum size in megabytes of the log file before it gets rotated
+	MaxSize int
+	// Maximum number of old log files to retain
+	MaxBackups int
+	// Maximum number of days to retain old log files
+	MaxAge int
+	// Whether to compress old log files
+	Compress bool
+	// Rotation mode: size (default), daily or hourly; time modes name files
+	// after the period, e.g. app-2026-10-18.log, and still honor MaxSize
+	Mode string
+	// Symlink created next to the files, always pointing at the current one (time modes only)
+	LinkName string
+	// Called with the path of every archived file, e.g. to upload it (time modes only)
+	OnRotate func(path string)
+}
+
+// rotation returns the rotation settings declared directly on LogConfig
+func (config LogConfig) rotation() RotationConfig {
+	return RotationConfig{
+		MaxSize:    config.MaxSize,
+		MaxBackups: config.MaxBackups,
+		MaxAge:     config.MaxAge,
+		Compress:   config.Compress,
+		Mode:       config.RotationMode,
+		LinkName:   config.RotationLink,
+		OnRotate:   config.OnRotate,
+	}
+}
+
+// outputs returns config.Outputs, or the single file plus optional console
+// outputs described by the legacy fields when none is declared
+func (config LogConfig) outputs() []OutputConfig {
+	if len(config.Outputs) > 0 {
+		return config.Outputs
+	}
+
+	outputs := []OutputConfig{{Type: OutputFile, Encoder: EncoderFormatter}}
+	if config.EnableConsole {
+		encoder := EncoderFormatter
+		if config.ConsoleEncoderConfig == "development" {
+			encoder = EncoderDevelopment
+		}
+		outputs = append(outputs, OutputConfig{Type: OutputStdout, Encoder: encoder})
+	}
+	return outputs
+}
+
+func newConsoleEncoderConfig() zapcore.EncoderConfig {
+	consoleEncoderConfig := zap.NewDevelopmentEncoderConfig()
+	consoleEncoderConfig.TimeKey = "time"
+	consoleEncoderConfig.LevelKey = "level"
+	consoleEncoderConfig.NameKey = "logger"
+	consoleEncoderConfig.CallerKey = "caller"
+	consoleEncoderConfig.MessageKey = "msg"
+	consoleEncoderConfig.StacktraceKey = "stacktrace"
+	consoleEncoderConfig.LineEnding = zapcore.DefaultLineEnding
+	consoleEncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	consoleEncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	consoleEncoderConfig.EncodeDuration = zapcore.StringDurationEncoder
+	consoleEncoderConfig.EncodeCaller = projectRootCallerEncoder
+	return consoleEncoderConfig
+}
+
+// newEncoder creates the encoder registered under name
+func newEncoder(name string) (zapcore.Encoder, error) {
+	switch name {
+	case "", EncoderFormatter:
+		return NewFormatterEncoder(), nil
+	case EncoderJSON:
+		encoderConfig := zap.NewProductionEncoderConfig()
+		encoderConfig.TimeKey = "time"
+		encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+		encoderConfig.EncodeDuration = zapcore.StringDurationEncoder
+		encoderConfig.EncodeCaller = projectRootCallerEncoder
+		return zapcore.NewJSONEncoder(encoderConfig), nil
+	case EncoderConsole:
+		encoderConfig := newConsoleEncoderConfig()
+		encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+		return zapcore.NewConsoleEncoder(encoderConfig), nil
+	case EncoderDevelopment:
+		return zapcore.NewConsoleEncoder(newConsoleEncoderConfig()), nil
+	}
+	return nil, fmt.Errorf("logger: unknown encoder %q", name)
+}
+
+// levelRange enables levels accepted by base and within [min, max]
+type levelRange struct {
+	base   zapcore.LevelEnabler
+	min    zapcore.Level
+	max    zapcore.Level
+	hasMax bool
+}
+
+func (r levelRange) Enabled(lvl zapcore.Level) bool {
+	return r.base.Enabled(lvl) && lvl >= r.min && (!r.hasMax || lvl <= r.max)
+}
+
+func newLevelRange(base zapcore.LevelEnabler, out OutputConfig) (zapcore.LevelEnabler, error) {
+	if out.MinLevel == "" && out.MaxLevel == "" {
+		return base, nil
+	}
+
+	r := levelRange{base: base, min: zapcore.DebugLevel - 1}
+	if out.MinLevel != "" {
+		if err := r.min.UnmarshalText([]byte(out.MinLevel)); err != nil {
+			return nil, err
+		}
+	}
+	if out.MaxLevel != "" {
+		if err := r.max.UnmarshalText([]byte(out.MaxLevel)); err != nil {
+			return nil, err
+		}
+		r.hasMax = true
+	}
+	return r, nil
+}
+
+// newOutputWriter opens the destination of out, the returned closer may be nil
+func newOutputWriter(config LogConfig, out OutputConfig) (zapcore.WriteSyncer, io.Closer, error) {
+	switch out.Type {
+	case OutputStdout:
+		return zapcore.AddSync(os.Stdout), nil, nil
+	case OutputStderr:
+		return zapcore.AddSync(os.Stderr), nil, nil
+	case OutputTCP:
+		sink, err := newTCPSink(out.TCP)
+		if err != nil {
+			return nil, nil, err
+		}
+		return sink, sink, nil
+	case OutputHTTP:
+		sink, err := newHTTPSink(out.HTTP)
+		if err != nil {
+			return nil, nil, err
+		}
+		return sink, sink, nil
+	case "", OutputFile:
+		filename := out.Filename
+		if filename == "" {
+			filename = config.AppName + ".log"
+		}
+		if !filepath.IsAbs(filename) {
+			filename = filepath.Join(config.FileDir, filename)
+		}
+
+		rotation := config.rotation()
+		if out.Rotation != nil {
+			rotation = *out.Rotation
+		}
+		if rotation.Mode != "" && rotation.Mode != RotateSize {
+			timeRotator, err := newTimeRotator(filename, rotation)
+			if err != nil {
+				return nil, nil, err
+			}
+			return timeRotator, timeRotator, nil
+		}
+		fileRotator := &lumberjack.Logger{
+			Filename:   filename,
+			MaxSize:    rotation.MaxSize,
+			MaxBackups: rotation.MaxBackups,
+			MaxAge:     rotation.MaxAge,
+			Compress:   rotation.Compress,
+		}
+		return zapcore.AddSync(fileRotator), fileRotator, nil
+	}
+	return nil, nil, fmt.Errorf("logger: unknown output type %q", out.Type)
+}
+
+// teeCore duplicates entries to several cores like zapcore.NewTee, but its
+// Write only reaches the cores enabled for the level of the entry, so wrapping
+// cores can call Write directly without losing the per-output level ranges
+type teeCore []zapcore.Core
+
+func newTeeCore(cores ...zapcore.Core) zapcore.Core {
+	if len(cores) == 1 {
+		return cores[0]
+	}
+	return teeCore(cores)
+}
+
+func (t teeCore) Enabled(level zapcore.Level) bool {
+	for _, c := range t {
+		if c.Enabled(level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t teeCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := make(teeCore, len(t))
+	for i, c := range t {
+		clone[i] = c.With(fields)
+	}
+	return clone
+}
+
+func (t teeCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	for _, c := range t {
+		ce = c.Check(ent, ce)
+	}
+	return ce
+}
+
+func (t teeCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	var errs []error
+	for _, c := range t {
+		if c.Enabled(ent.Level) {
+			if err := c.Write(ent, fields); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t teeCore) Sync() error {
+	var errs []error
+	for _, c := range t {
+		if err := c.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// newOutputCore builds the core writing entries enabled by level to out
+func newOutputCore(config LogConfig, out OutputConfig, level zapcore.LevelEnabler) (zapcore.Core, io.Closer, error) {
+	encoder := out.Encoder
+	if encoder == "" && (out.Type == OutputTCP || out.Type == OutputHTTP) {
+		encoder = EncoderJSON
+	}
+	enc, err := newEncoder(encoder)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	enabler, err := newLevelRange(level, out)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// syslog needs the level of each entry to build its header
+	if out.Type == OutputSyslog {
+		core, err := newSyslogCore(out.Syslog, config.AppName, enc, enabler)
+		if err != nil {
+			return nil, nil, err
+		}
+		return core, core, nil
+	}
+
+	ws, closer, err := newOutputWriter(config, out)
+	if err != nil {
+		return nil, nil, err
+	}
+	return zapcore.NewCore(enc, ws, enabler), closer, nil
+}
@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestInitLoggerOutputs(t *testing.T) {
+	dir := t.TempDir()
+	config := DefaultConfig()
+	config.FileDir = dir
+	config.AppName = "app"
+	config.Level = "debug"
+	config.Outputs = []OutputConfig{
+		{Type: OutputFile},
+		{Type: OutputFile, Filename: "app.error.log", MinLevel: "warn"},
+		{Type: OutputFile, Filename: "app.debug.json", MaxLevel: "debug", Encoder: EncoderJSON},
+	}
+	assert.NoError(t, InitLogger(config))
+	defer Close()
+
+	Debugf("debug %d", 1)
+	Infof("info %d", 2)
+	Warnf("warn %d", 3)
+	Errorf("error %d", 4)
+	assert.NoError(t, Close())
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		assert.NoError(t, err)
+		return string(data)
+	}
+
+	all := read("app.log")
+	assert.Equal(t, 4, strings.Count(all, "\n"))
+
+	errs := read("app.error.log")
+	assert.Equal(t, 2, strings.Count(errs, "\n"))
+	assert.Contains(t, errs, "WARN")
+	assert.Contains(t, errs, "error 4")
+	assert.NotContains(t, errs, "info 2")
+
+	debug := read("app.debug.json")
+	assert.Equal(t, 1, strings.Count(debug, "\n"))
+	assert.Contains(t, debug, `"msg":"debug 1"`)
+}
+
+func TestInitLoggerInvalidOutput(t *testing.T) {
+	config := DefaultConfig()
+	config.FileDir = t.TempDir()
+
+	config.Outputs = []OutputConfig{{Type: "kafka"}}
+	assert.Error(t, InitLogger(config))
+
+	config.Outputs = []OutputConfig{{Type: OutputStderr, Encoder: "xml"}}
+	assert.Error(t, InitLogger(config))
+
+	config.Outputs = []OutputConfig{{Type: OutputStderr, MinLevel: "loud"}}
+	assert.Error(t, InitLogger(config))
+}
+
+func TestLegacyOutputs(t *testing.T) {
+	config := DefaultConfig()
+	outputs := config.outputs()
+	assert.Len(t, outputs, 2)
+	assert.Equal(t, OutputFile, outputs[0].Type)
+	assert.Equal(t, EncoderDevelopment, outputs[1].Encoder)
+
+	config.EnableConsole = false
+	assert.Len(t, config.outputs(), 1)
+}
+
+func TestLevelRange(t *testing.T) {
+	enabler, err := newLevelRange(zapcore.DebugLevel, OutputConfig{MinLevel: "info", MaxLevel: "warn"})
+	assert.NoError(t, err)
+	assert.False(t, enabler.Enabled(zapcore.DebugLevel))
+	assert.True(t, enabler.Enabled(zapcore.InfoLevel))
+	assert.True(t, enabler.Enabled(zapcore.WarnLevel))
+	assert.False(t, enabler.Enabled(zapcore.ErrorLevel))
+}
+
+func TestTeeCoreWrite(t *testing.T) {
+	debug, debugLogs := observer.New(zapcore.DebugLevel)
+	errs, errLogs := observer.New(zapcore.ErrorLevel)
+	core := newTeeCore(debug, errs)
+
+	// Write skips the cores not enabled for the level
+	assert.NoError(t, core.Write(zapcore.Entry{Level: zapcore.InfoLevel, Message: "info"}, nil))
+	assert.NoError(t, core.Write(zapcore.Entry{Level: zapcore.ErrorLevel, Message: "error"}, nil))
+	assert.Equal(t, 2, debugLogs.Len())
+	assert.Equal(t, 1, errLogs.Len())
+	assert.Equal(t, "error", errLogs.All()[0].Message)
+	assert.True(t, core.Enabled(zapcore.DebugLevel))
+}
@@ -0,0 +1,379 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// TimeLayout is the timestamp layout written by the formatter encoder
+const TimeLayout = "2006-01-02 15:04:05.000"
+
+// LogEntry is a line written by the formatter encoder parsed back into its parts
+type LogEntry struct {
+	Time    time.Time     `json:"time"`
+	App     string        `json:"app"`
+	GID     uint64        `json:"gid"`
+	Level   zapcore.Level `json:"level"`
+	Func    string        `json:"func"`
+	File    string        `json:"file"`
+	Line    int           `json:"line"`
+	Message string        `json:"msg"`
+	// Fields holds the k=v pairs following the message, values as written
+	Fields map[string]string `json:"fields,omitempty"`
+	// Keys keeps the order in which the fields were written
+	Keys []string `json:"-"`
+	// Raw is the text the entry was parsed from
+	Raw string `json:"-"`
+}
+
+// TraceID returns the trace_id field of the entry
+func (e *LogEntry) TraceID() string {
+	return e.Fields["trace_id"]
+}
+
+// UserID returns the user_id field of the entry
+func (e *LogEntry) UserID() string {
+	return e.Fields["user_id"]
+}
+
+// ParseLine parses a line written by the formatter encoder:
+//
+//	ts[app][gid-N]LEVEL[func][-]file:line msg k=v k=v
+//
+// Fields start at the first " key=" following the message, so a message
+// containing such text is split there as well
+func ParseLine(line string) (*LogEntry, error) {
+	line = strings.TrimRight(line, "\r\n")
+	if len(line) < len(TimeLayout) {
+		return nil, fmt.Errorf("logger: line too short: %q", line)
+	}
+
+	e := &LogEntry{Raw: line}
+	t, err := time.ParseInLocation(TimeLayout, line[:len(TimeLayout)], time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("logger: bad timestamp: %w", err)
+	}
+	e.Time = t
+	rest := line[len(TimeLayout):]
+
+	var gid string
+	if e.App, rest, err = bracketed(rest); err != nil {
+		return nil, err
+	}
+	if gid, rest, err = bracketed(rest); err != nil {
+		return nil, err
+	}
+	if e.GID, err = strconv.ParseUint(strings.TrimPrefix(gid, "gid-"), 10, 64); err != nil {
+		return nil, fmt.Errorf("logger: bad goroutine id %q", gid)
+	}
+
+	i := strings.IndexByte(rest, '[')
+	if i < 0 {
+		return nil, fmt.Errorf("logger: missing level in %q", line)
+	}
+	if err := e.Level.UnmarshalText([]byte(rest[:i])); err != nil {
+		return nil, fmt.Errorf("logger: bad level %q", rest[:i])
+	}
+	rest = rest[i+1:]
+
+	// generic function names contain brackets, the function ends at "][-]"
+	i = strings.Index(rest, "][-]")
+	if i < 0 {
+		return nil, fmt.Errorf("logger: missing caller in %q", line)
+	}
+	e.Func, rest = rest[:i], rest[i+len("][-]"):]
+
+	caller, msg, _ := strings.Cut(rest, " ")
+	colon := strings.LastIndexByte(caller, ':')
+	if colon < 0 {
+		return nil, fmt.Errorf("logger: bad caller %q", caller)
+	}
+	e.File = caller[:colon]
+	if e.Line, err = strconv.Atoi(caller[colon+1:]); err != nil {
+		return nil, fmt.Errorf("logger: bad caller %q", caller)
+	}
+
+	e.Message, e.Keys, e.Fields = splitFields(msg)
+	return e, nil
+}
+
+// bracketed returns the text inside the leading [...] of s and what follows it
+func bracketed(s string) (string, string, error) {
+	if !strings.HasPrefix(s, "[") {
+		return "", "", fmt.Errorf("logger: expected '[' at %q", s)
+	}
+	i := strings.IndexByte(s, ']')
+	if i < 0 {
+		return "", "", fmt.Errorf("logger: unterminated '[' at %q", s)
+	}
+	return s[1:i], s[i+1:], nil
+}
+
+// splitFields separates the message from the trailing " key=value" pairs
+func splitFields(s string) (string, []string, map[string]string) {
+	starts := fieldStarts(s)
+	if len(starts) == 0 {
+		return s, nil, nil
+	}
+
+	keys := make([]string, 0, len(starts))
+	fields := make(map[string]string, len(starts))
+	for n, start := range starts {
+		end := len(s)
+		if n+1 < len(starts) {
+			end = starts[n+1]
+		}
+		key, value, _ := strings.Cut(s[start+1:end], "=")
+		if _, ok := fields[key]; !ok {
+			keys = append(keys, key)
+		}
+		fields[key] = value
+	}
+	return s[:starts[0]], keys, fields
+}
+
+// fieldStarts returns the offsets of the spaces preceding "key=" in s
+func fieldStarts(s string) []int {
+	var starts []int
+	for i := 0; i < len(s); i++ {
+		if s[i] != ' ' {
+			continue
+		}
+		j := i + 1
+		for j < len(s) && isFieldKeyByte(s[j]) {
+			j++
+		}
+		if j > i+1 && j < len(s) && s[j] == '=' {
+			starts = append(starts, i)
+			i = j
+		}
+	}
+	return starts
+}
+
+func isFieldKeyByte(c byte) bool {
+	return c == '_' || c == '.' || c == '-' ||
+		'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// LogReader reads entries from a log file written by the formatter encoder,
+// lines that do not start an entry are appended to the message of the previous one
+type LogReader struct {
+	scanner *bufio.Scanner
+	closers []io.Closer
+	pending *LogEntry
+	err     error
+}
+
+// NewLogReader returns a reader of the entries in r, gzip input is detected and decompressed
+func NewLogReader(r io.Reader) (*LogReader, error) {
+	br := bufio.NewReader(r)
+	lr := &LogReader{}
+	if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("logger: open gzip: %w", err)
+		}
+		lr.closers = append(lr.closers, gz)
+		r = gz
+	} else {
+		r = br
+	}
+
+	lr.scanner = bufio.NewScanner(r)
+	lr.scanner.Buffer(make([]byte, 64*1024), 16*megabyte)
+	return lr, nil
+}
+
+// OpenLogFile opens a log file for reading, including files rotated and gzipped by lumberjack
+func OpenLogFile(path string) (*LogReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	lr, err := NewLogReader(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	lr.closers = append(lr.closers, f)
+	return lr, nil
+}
+
+// Next returns the next entry, or io.EOF once the input is exhausted
+func (r *LogReader) Next() (*LogEntry, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	for r.scanner.Scan() {
+		line := r.scanner.Text()
+		e, err := ParseLine(line)
+		if err != nil {
+			if r.pending != nil {
+				r.pending.Message += "\n" + line
+				r.pending.Raw += "\n" + line
+			}
+			continue
+		}
+		prev := r.pending
+		r.pending = e
+		if prev != nil {
+			return prev, nil
+		}
+	}
+
+	r.err = r.scanner.Err()
+	if r.err == nil {
+		r.err = io.EOF
+	}
+	if e := r.pending; e != nil {
+		r.pending = nil
+		return e, nil
+	}
+	return nil, r.err
+}
+
+// Close closes the underlying file
+func (r *LogReader) Close() error {
+	var err error
+	for _, c := range r.closers {
+		if cerr := c.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Field predicate operators accepted by ParseFieldPredicate
+const (
+	OpEqual     = "="
+	OpNotEqual  = "!="
+	OpContains  = "~"
+	OpGreater   = ">"
+	OpGreaterEq = ">="
+	OpLess      = "<"
+	OpLessEq    = "<="
+)
+
+// FieldPredicate matches entries by the value of a field
+type FieldPredicate struct {
+	Key   string
+	Op    string
+	Value string
+}
+
+// ParseFieldPredicate parses expressions like status=500, path~/orders or cost>=200ms
+func ParseFieldPredicate(expr string) (FieldPredicate, error) {
+	i := strings.IndexAny(expr, "=!~<>")
+	if i <= 0 {
+		return FieldPredicate{}, fmt.Errorf("logger: bad field predicate %q", expr)
+	}
+	p := FieldPredicate{Key: expr[:i]}
+	rest := expr[i:]
+	for _, op := range []string{OpNotEqual, OpGreaterEq, OpLessEq, OpEqual, OpContains, OpGreater, OpLess} {
+		if strings.HasPrefix(rest, op) {
+			p.Op, p.Value = op, rest[len(op):]
+			return p, nil
+		}
+	}
+	return FieldPredicate{}, fmt.Errorf("logger: bad field predicate %q", expr)
+}
+
+// Match reports whether the field of e satisfies the predicate,
+// ordering compares numbers and durations by value and anything else as text
+func (p FieldPredicate) Match(e *LogEntry) bool {
+	v, ok := e.Fields[p.Key]
+	switch p.Op {
+	case OpEqual:
+		return ok && v == p.Value
+	case OpNotEqual:
+		return !ok || v != p.Value
+	case OpContains:
+		return ok && strings.Contains(v, p.Value)
+	}
+	if !ok {
+		return false
+	}
+
+	c := compareValues(v, p.Value)
+	switch p.Op {
+	case OpGreater:
+		return c > 0
+	case OpGreaterEq:
+		return c >= 0
+	case OpLess:
+		return c < 0
+	case OpLessEq:
+		return c <= 0
+	}
+	return false
+}
+
+func compareValues(a, b string) int {
+	if x, err := strconv.ParseFloat(a, 64); err == nil {
+		if y, err := strconv.ParseFloat(b, 64); err == nil {
+			return compareOrdered(x, y)
+		}
+	}
+	if x, err := time.ParseDuration(a); err == nil {
+		if y, err := time.ParseDuration(b); err == nil {
+			return compareOrdered(x, y)
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+func compareOrdered[T float64 | time.Duration](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// LogFilter selects entries read back from log files, zero values match everything
+type LogFilter struct {
+	Since time.Time
+	Until time.Time
+	// Level selects the levels to keep, nil keeps all of them
+	Level   zapcore.LevelEnabler
+	TraceID string
+	UserID  string
+	Fields  []FieldPredicate
+}
+
+// Match reports whether e passes every condition of the filter
+func (f *LogFilter) Match(e *LogEntry) bool {
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.Time.Before(f.Until) {
+		return false
+	}
+	if f.Level != nil && !f.Level.Enabled(e.Level) {
+		return false
+	}
+	if f.TraceID != "" && e.TraceID() != f.TraceID {
+		return false
+	}
+	if f.UserID != "" && e.UserID() != f.UserID {
+		return false
+	}
+	for _, p := range f.Fields {
+		if !p.Match(e) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestParseLine(t *testing.T) {
+	var buf bytes.Buffer
+	core := zapcore.NewCore(NewFormatterEncoder(), zapcore.AddSync(&buf), zapcore.DebugLevel)
+	zap.New(core, zap.AddCaller()).Warn("slow request took 3s",
+		zap.String("trace_id", "t-1"), zap.Int("status", 502), zap.Duration("cost", 1500*time.Millisecond))
+
+	e, err := ParseLine(buf.String())
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultAppsName, e.App)
+	assert.NotZero(t, e.GID)
+	assert.Equal(t, zapcore.WarnLevel, e.Level)
+	assert.Equal(t, "logger.TestParseLine", e.Func)
+	assert.Equal(t, "logger_reader_test.go", e.File)
+	assert.NotZero(t, e.Line)
+	assert.Equal(t, "slow request took 3s", e.Message)
+	assert.Equal(t, []string{"trace_id", "status", "cost"}, e.Keys)
+	assert.Equal(t, "t-1", e.TraceID())
+	assert.Equal(t, "1.5s", e.Fields["cost"])
+	assert.WithinDuration(t, time.Now(), e.Time, time.Minute)
+
+	e, err = ParseLine("2026-10-18 09:30:00.123[api][gid-7]ERROR[svc.Run[...]][-]svc.go:12 failed: a b user_id=u-2 err=dial tcp: refused")
+	assert.NoError(t, err)
+	assert.Equal(t, "svc.Run[...]", e.Func)
+	assert.Equal(t, "failed: a b", e.Message)
+	assert.Equal(t, "u-2", e.UserID())
+	assert.Equal(t, "dial tcp: refused", e.Fields["err"])
+	assert.Equal(t, 123*int(time.Millisecond), e.Time.Nanosecond())
+
+	for _, bad := range []string{"", "not a log line at all, really", "2026-10-18 09:30:00.123[api][gid-x]INFO[f][-]a.go:1 m"} {
+		_, err := ParseLine(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestLogReader(t *testing.T) {
+	lines := "2026-10-18 09:00:00.000[app][gid-1]INFO[main.main][-]main.go:10 started\n" +
+		"2026-10-18 09:05:00.000[app][gid-2]ERROR[main.work][-]main.go:20 panic recovered trace_id=t-9 status=500\n" +
+		"goroutine 2 [running]:\n" +
+		"2026-10-18 09:10:00.000[app][gid-3]DEBUG[main.work][-]main.go:30 tick cost=250ms\n"
+
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "app.log")
+	assert.NoError(t, os.WriteFile(plain, []byte(lines), 0644))
+
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	_, _ = w.Write([]byte(lines))
+	assert.NoError(t, w.Close())
+	archived := filepath.Join(dir, "app-2026-10-18T09-10-00.000.log.gz")
+	assert.NoError(t, os.WriteFile(archived, gz.Bytes(), 0644))
+
+	for _, path := range []string{plain, archived} {
+		r, err := OpenLogFile(path)
+		assert.NoError(t, err)
+
+		var entries []*LogEntry
+		for {
+			e, err := r.Next()
+			if err == io.EOF {
+				break
+			}
+			assert.NoError(t, err)
+			entries = append(entries, e)
+		}
+		assert.NoError(t, r.Close())
+
+		assert.Len(t, entries, 3, path)
+		assert.Equal(t, "panic recovered\ngoroutine 2 [running]:", entries[1].Message)
+		assert.True(t, strings.HasSuffix(entries[1].Raw, "[running]:"))
+		assert.Equal(t, zapcore.DebugLevel, entries[2].Level)
+
+		_, err = r.Next()
+		assert.Equal(t, io.EOF, err)
+	}
+}
+
+func TestLogFilter(t *testing.T) {
+	e, err := ParseLine("2026-10-18 09:05:00.000[app][gid-2]ERROR[main.work][-]main.go:20 failed trace_id=t-9 user_id=u-1 status=500 cost=250ms path=/orders/7")
+	assert.NoError(t, err)
+
+	at := func(s string) time.Time {
+		ts, _ := time.ParseInLocation(TimeLayout, s, time.Local)
+		return ts
+	}
+	pred := func(expr string) FieldPredicate {
+		p, err := ParseFieldPredicate(expr)
+		assert.NoError(t, err, expr)
+		return p
+	}
+
+	assert.True(t, (&LogFilter{}).Match(e))
+	assert.True(t, (&LogFilter{
+		Since:   at("2026-10-18 09:00:00.000"),
+		Until:   at("2026-10-18 09:10:00.000"),
+		Level:   zapcore.WarnLevel,
+		TraceID: "t-9",
+		UserID:  "u-1",
+		Fields:  []FieldPredicate{pred("status>=500"), pred("cost<1s"), pred("path~/orders"), pred("region!=eu")},
+	}).Match(e))
+
+	assert.False(t, (&LogFilter{Until: at("2026-10-18 09:05:00.000")}).Match(e))
+	assert.False(t, (&LogFilter{Level: zapcore.DPanicLevel}).Match(e))
+	assert.False(t, (&LogFilter{TraceID: "t-1"}).Match(e))
+	assert.False(t, (&LogFilter{Fields: []FieldPredicate{pred("status>500")}}).Match(e))
+	assert.False(t, (&LogFilter{Fields: []FieldPredicate{pred("cost>1s")}}).Match(e))
+	assert.False(t, (&LogFilter{Fields: []FieldPredicate{pred("region=eu")}}).Match(e))
+
+	for _, bad := range []string{"status", "=500", "status?5"} {
+		_, err := ParseFieldPredicate(bad)
+		assert.Error(t, err, bad)
+	}
+}
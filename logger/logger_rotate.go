@@ -0,0 +1,345 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rotation modes accepted by RotationConfig.Mode
+const (
+	RotateSize   = "size"
+	RotateDaily  = "daily"
+	RotateHourly = "hourly"
+)
+
+const megabyte = 1024 * 1024
+
+// timeRotator writes to files named after the current period, e.g. app-2026-10-18.log,
+// additionally splitting a period into app-2026-10-18.1.log, ... once MaxSize is reached
+type timeRotator struct {
+	mu sync.Mutex
+
+	dir    string
+	base   string
+	ext    string
+	layout string
+
+	maxSize    int64
+	maxBackups int
+	maxAge     time.Duration
+	compress   bool
+	link       string
+	onRotate   func(path string)
+
+	file    *os.File
+	current string
+	key     string
+	seq     int
+	size    int64
+
+	// serializes compression and retention of archived files
+	millMu sync.Mutex
+	wg     sync.WaitGroup
+
+	now func() time.Time
+}
+
+func newTimeRotator(filename string, rotation RotationConfig) (*timeRotator, error) {
+	var layout string
+	switch rotation.Mode {
+	case RotateDaily:
+		layout = "2006-01-02"
+	case RotateHourly:
+		layout = "2006-01-02-15"
+	default:
+		return nil, fmt.Errorf("logger: unknown rotation mode %q", rotation.Mode)
+	}
+
+	ext := filepath.Ext(filename)
+	r := &timeRotator{
+		dir:        filepath.Dir(filename),
+		base:       strings.TrimSuffix(filepath.Base(filename), ext),
+		ext:        ext,
+		layout:     layout,
+		maxSize:    int64(rotation.MaxSize) * megabyte,
+		maxBackups: rotation.MaxBackups,
+		maxAge:     time.Duration(rotation.MaxAge) * 24 * time.Hour,
+		compress:   rotation.Compress,
+		onRotate:   rotation.OnRotate,
+		now:        time.Now,
+	}
+	if rotation.LinkName != "" {
+		r.link = filepath.Join(r.dir, rotation.LinkName)
+	}
+	return r, nil
+}
+
+// filename returns the path of the seq-th file of the period identified by key
+func (r *timeRotator) filename(key string, seq int) string {
+	name := r.base + "-" + key
+	if seq > 0 {
+		name += "." + strconv.Itoa(seq)
+	}
+	return filepath.Join(r.dir, name+r.ext)
+}
+
+func (r *timeRotator) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := r.now().Format(r.layout)
+	switch {
+	case r.file == nil:
+		if err := r.openPeriod(key); err != nil {
+			return 0, err
+		}
+		r.scheduleMill("")
+	case key != r.key:
+		if err := r.rotate(func() error { return r.openPeriod(key) }); err != nil {
+			return 0, err
+		}
+	case r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize:
+		if err := r.rotate(func() error { return r.open(key, r.seq+1) }); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// openPeriod continues the last file written for key, or starts a new one after
+// it when that file has already been archived
+func (r *timeRotator) openPeriod(key string) error {
+	seq := -1
+	prefix := r.base + "-" + key
+	matches, _ := filepath.Glob(filepath.Join(r.dir, prefix+"*"))
+	for _, m := range matches {
+		name := filepath.Base(m)
+		archived := strings.HasSuffix(name, ".gz")
+		name = strings.TrimSuffix(name, ".gz")
+		if !strings.HasSuffix(name, r.ext) {
+			continue
+		}
+		rest := strings.TrimSuffix(strings.TrimPrefix(name, prefix), r.ext)
+		n := 0
+		if rest != "" {
+			if !strings.HasPrefix(rest, ".") {
+				continue
+			}
+			var err error
+			if n, err = strconv.Atoi(rest[1:]); err != nil {
+				continue
+			}
+		}
+		if archived {
+			n++
+		}
+		if n > seq {
+			seq = n
+		}
+	}
+	if seq < 0 {
+		seq = 0
+	}
+	return r.open(key, seq)
+}
+
+func (r *timeRotator) open(key string, seq int) error {
+	name := r.filename(key, seq)
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	r.file, r.current, r.key, r.seq, r.size = f, name, key, seq, info.Size()
+	r.updateLink(name)
+	return nil
+}
+
+// updateLink points the configured symlink at name, failures are ignored
+// since logging must not stop on platforms without symlinks
+func (r *timeRotator) updateLink(name string) {
+	if r.link == "" {
+		return
+	}
+	tmp := r.link + ".tmp"
+	_ = os.Remove(tmp)
+	if err := os.Symlink(filepath.Base(name), tmp); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, r.link); err != nil {
+		_ = os.Remove(tmp)
+	}
+}
+
+// rotate closes the current file, opens the next one and archives the old file
+func (r *timeRotator) rotate(openNext func() error) error {
+	old := r.file.Name()
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	r.file = nil
+	if err := openNext(); err != nil {
+		return err
+	}
+	r.scheduleMill(old)
+	return nil
+}
+
+func (r *timeRotator) scheduleMill(rotated string) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.mill(rotated)
+	}()
+}
+
+// currentName returns the path of the file being written, or last written once closed
+func (r *timeRotator) currentName() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// mill compresses the rotated file, runs the hook on it and enforces retention
+func (r *timeRotator) mill(rotated string) {
+	r.millMu.Lock()
+	defer r.millMu.Unlock()
+
+	if rotated != "" {
+		archived := rotated
+		if r.compress {
+			// an earlier retention pass may already have compressed it
+			if _, err := os.Stat(rotated + ".gz"); err == nil || gzipFile(rotated) == nil {
+				archived = rotated + ".gz"
+			}
+		}
+		if r.onRotate != nil {
+			r.onRotate(archived)
+		}
+	}
+
+	archives := r.archives(r.currentName())
+	cutoff := r.now().Add(-r.maxAge)
+	for i, a := range archives {
+		if (r.maxBackups > 0 && i >= r.maxBackups) || (r.maxAge > 0 && a.ModTime().Before(cutoff)) {
+			_ = os.Remove(filepath.Join(r.dir, a.Name()))
+			continue
+		}
+		if r.compress && !strings.HasSuffix(a.Name(), ".gz") {
+			_ = gzipFile(filepath.Join(r.dir, a.Name()))
+		}
+	}
+}
+
+// archives lists the rotated files other than current, newest first
+func (r *timeRotator) archives(current string) []os.FileInfo {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return nil
+	}
+
+	var archives []os.FileInfo
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || name == filepath.Base(current) || !r.isArchiveName(name) {
+			continue
+		}
+		if !strings.HasSuffix(name, r.ext) && !strings.HasSuffix(name, r.ext+".gz") {
+			continue
+		}
+		if info, err := e.Info(); err == nil && info.Mode().IsRegular() {
+			archives = append(archives, info)
+		}
+	}
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].ModTime().After(archives[j].ModTime())
+	})
+	return archives
+}
+
+// isArchiveName reports whether name belongs to this rotator, the period key
+// following the base name keeps e.g. app-error-*.log apart from app-*.log
+func (r *timeRotator) isArchiveName(name string) bool {
+	prefix := r.base + "-"
+	return len(name) > len(prefix) && strings.HasPrefix(name, prefix) && isDigit(name[len(prefix)])
+}
+
+// gzipFile replaces name with name.gz
+func gzipFile(name string) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(name + ".gz")
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	// keep the original modification time so retention still orders by age
+	_ = os.Chtimes(name+".gz", info.ModTime(), info.ModTime())
+	return os.Remove(name)
+}
+
+func (r *timeRotator) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	return r.file.Sync()
+}
+
+// Close closes the current file and waits for pending archive work
+func (r *timeRotator) Close() error {
+	r.mu.Lock()
+	var err error
+	if r.file != nil {
+		err = r.file.Close()
+		r.file = nil
+	}
+	r.mu.Unlock()
+	r.wg.Wait()
+	return err
+}
@@ -0,0 +1,123 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testClock is a goroutine safe fake clock, archive work reads it in the background
+type testClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *testClock) Add(d time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+	return c.t
+}
+
+func listDir(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestTimeRotatorDaily(t *testing.T) {
+	dir := t.TempDir()
+	var mu sync.Mutex
+	var rotated []string
+	r, err := newTimeRotator(filepath.Join(dir, "app.log"), RotationConfig{
+		Mode:     RotateDaily,
+		Compress: true,
+		LinkName: "latest",
+		OnRotate: func(path string) {
+			mu.Lock()
+			rotated = append(rotated, filepath.Base(path))
+			mu.Unlock()
+		},
+	})
+	assert.NoError(t, err)
+
+	clock := &testClock{t: time.Date(2026, 10, 18, 23, 59, 0, 0, time.Local)}
+	r.now = clock.Now
+
+	_, err = r.Write([]byte("first\n"))
+	assert.NoError(t, err)
+	link, err := os.Readlink(filepath.Join(dir, "latest"))
+	assert.NoError(t, err)
+	assert.Equal(t, "app-2026-10-18.log", link)
+
+	clock.Add(2 * time.Minute)
+	_, err = r.Write([]byte("second\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+
+	assert.Equal(t, []string{"app-2026-10-18.log.gz", "app-2026-10-19.log", "latest"}, listDir(t, dir))
+	assert.Equal(t, []string{"app-2026-10-18.log.gz"}, rotated)
+	link, err = os.Readlink(filepath.Join(dir, "latest"))
+	assert.NoError(t, err)
+	assert.Equal(t, "app-2026-10-19.log", link)
+
+	// reopening continues the current file of the period
+	_, err = r.Write([]byte("third\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+	data, err := os.ReadFile(filepath.Join(dir, "app-2026-10-19.log"))
+	assert.NoError(t, err)
+	assert.Equal(t, "second\nthird\n", string(data))
+}
+
+func TestTimeRotatorSizeAndRetention(t *testing.T) {
+	dir := t.TempDir()
+	// a file of another output sharing the prefix must be left alone
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "app-error-2026-10-01.log"), nil, 0644))
+
+	r, err := newTimeRotator(filepath.Join(dir, "app.log"), RotationConfig{
+		Mode:       RotateHourly,
+		MaxBackups: 2,
+	})
+	assert.NoError(t, err)
+	r.maxSize = 10
+	clock := &testClock{t: time.Date(2026, 10, 18, 8, 0, 0, 0, time.Local)}
+	r.now = clock.Now
+
+	for i := 0; i < 4; i++ {
+		_, err = r.Write([]byte("0123456789"))
+		assert.NoError(t, err)
+		// distinct modification times keep the retention order stable
+		mtime := clock.Add(time.Second)
+		_ = os.Chtimes(r.currentName(), mtime, mtime)
+	}
+	assert.NoError(t, r.Close())
+
+	assert.Equal(t, []string{
+		"app-2026-10-18-08.1.log",
+		"app-2026-10-18-08.2.log",
+		"app-2026-10-18-08.3.log",
+		"app-error-2026-10-01.log",
+	}, listDir(t, dir))
+}
+
+func TestTimeRotatorInvalidMode(t *testing.T) {
+	_, err := newTimeRotator("app.log", RotationConfig{Mode: "weekly"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,282 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// SamplingConfig limits how many entries sharing a level and message template are written per interval
+type SamplingConfig struct {
+	// Length of a sampling window
+	Interval time.Duration
+	// Number of entries logged per window before sampling kicks in
+	First int
+	// After First, only every Thereafter-th entry is logged (0 drops the rest)
+	Thereafter int
+	// Per-level overrides keyed by level name, e.g. "error"
+	Levels map[string]SamplingRule
+}
+
+// SamplingRule is the First/Thereafter pair applied to a single level
+type SamplingRule struct {
+	First      int
+	Thereafter int
+}
+
+// SuppressionConfig collapses identical messages into a periodic summary.
+// Entries are identical when their level, message and fields, including
+// those added with With, all match.
+type SuppressionConfig struct {
+	// Window during which repeats of a message are counted instead of written
+	Interval time.Duration
+}
+
+// rule returns the sampling rule for the given level
+func (c *SamplingConfig) rule(level zapcore.Level) SamplingRule {
+	if r, ok := c.Levels[level.String()]; ok {
+		return r
+	}
+	return SamplingRule{First: c.First, Thereafter: c.Thereafter}
+}
+
+// messageTemplate strips digits from msg so that formatted messages
+// like "timeout after 31ms" and "timeout after 45ms" share a key
+func messageTemplate(msg string) string {
+	if strings.IndexAny(msg, "0123456789") == -1 {
+		return msg
+	}
+	var b strings.Builder
+	b.Grow(len(msg))
+	inDigits := false
+	for i := 0; i < len(msg); i++ {
+		c := msg[i]
+		if c >= '0' && c <= '9' {
+			if !inDigits {
+				b.WriteByte('#')
+			}
+			inDigits = true
+			continue
+		}
+		inDigits = false
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+type sampleCounters struct {
+	mu        sync.Mutex
+	windowEnd time.Time
+	counts    map[string]int
+}
+
+// inc increments the counter for key, resetting all counters when the window has elapsed
+func (s *sampleCounters) inc(key string, t time.Time, interval time.Duration) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !t.Before(s.windowEnd) {
+		s.counts = make(map[string]int, len(s.counts))
+		s.windowEnd = t.Add(interval)
+	}
+	s.counts[key]++
+	return s.counts[key]
+}
+
+// samplerCore drops entries exceeding the configured per-interval budget
+type samplerCore struct {
+	zapcore.Core
+	cfg      *SamplingConfig
+	counters *sampleCounters
+}
+
+func newSamplerCore(core zapcore.Core, cfg *SamplingConfig) zapcore.Core {
+	if cfg == nil || cfg.Interval <= 0 {
+		return core
+	}
+	return &samplerCore{
+		Core:     core,
+		cfg:      cfg,
+		counters: &sampleCounters{},
+	}
+}
+
+func (c *samplerCore) With(fields []zapcore.Field) zapcore.Core {
+	return &samplerCore{
+		Core:     c.Core.With(fields),
+		cfg:      c.cfg,
+		counters: c.counters,
+	}
+}
+
+func (c *samplerCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write counts ent and passes it on while the budget of its key allows,
+// counting here keeps entries written by suppressCore within the budget too
+func (c *samplerCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	rule := c.cfg.rule(ent.Level)
+	if rule.First <= 0 {
+		return c.Core.Write(ent, fields)
+	}
+
+	key := ent.Level.String() + "\x00" + messageTemplate(ent.Message)
+	n := c.counters.inc(key, ent.Time, c.cfg.Interval)
+	if n <= rule.First || (rule.Thereafter > 0 && (n-rule.First)%rule.Thereafter == 0) {
+		return c.Core.Write(ent, fields)
+	}
+	return nil
+}
+
+type repeatState struct {
+	ent    zapcore.Entry
+	core   zapcore.Core
+	fields []zapcore.Field
+	count  int
+}
+
+// suppressor tracks repeated messages shared by all cores derived from one suppressCore
+type suppressor struct {
+	mu       sync.Mutex
+	interval time.Duration
+	seen     map[string]*repeatState
+	done     chan struct{}
+	once     sync.Once
+}
+
+// suppressCore writes the first occurrence of a message and counts the identical
+// ones following it within the interval, emitting "message repeated N times" afterwards
+type suppressCore struct {
+	zapcore.Core
+	s *suppressor
+	// encoded With fields, part of every key
+	context string
+}
+
+func newSuppressCore(core zapcore.Core, cfg *SuppressionConfig) (zapcore.Core, *suppressor) {
+	if cfg == nil || cfg.Interval <= 0 {
+		return core, nil
+	}
+	s := &suppressor{
+		interval: cfg.Interval,
+		seen:     make(map[string]*repeatState),
+		done:     make(chan struct{}),
+	}
+	go s.run()
+	return &suppressCore{Core: core, s: s}, s
+}
+
+func (c *suppressCore) With(fields []zapcore.Field) zapcore.Core {
+	return &suppressCore{
+		Core:    c.Core.With(fields),
+		s:       c.s,
+		context: c.context + encodeFields(fields),
+	}
+}
+
+// Check defers the decision to Write, where the fields of the entry are known
+func (c *suppressCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *suppressCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	key := ent.Level.String() + "\x00" + ent.Message + "\x00" + c.context + encodeFields(fields)
+	c.s.mu.Lock()
+	st, ok := c.s.seen[key]
+	if ok && ent.Time.Sub(st.ent.Time) < c.s.interval {
+		st.count++
+		c.s.mu.Unlock()
+		return nil
+	}
+	c.s.seen[key] = &repeatState{ent: ent, core: c.Core, fields: fields}
+	c.s.mu.Unlock()
+
+	if ok {
+		st.summarize(ent.Time)
+	}
+	return c.Core.Write(ent, fields)
+}
+
+func (c *suppressCore) Sync() error {
+	c.s.flush(time.Time{})
+	return c.Core.Sync()
+}
+
+// summarize writes the repeat summary for st if any message was suppressed
+func (st *repeatState) summarize(now time.Time) {
+	if st.count == 0 {
+		return
+	}
+	ent := st.ent
+	ent.Time = now
+	ent.Message = fmt.Sprintf("message repeated %d times: %s", st.count, st.ent.Message)
+	ent.Stack = ""
+	if st.core.Enabled(ent.Level) {
+		_ = st.core.Write(ent, st.fields)
+	}
+}
+
+// encodeFields renders fields into a stable string usable as part of a map key
+func encodeFields(fields []zapcore.Field) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	// fmt prints map keys in sorted order
+	return fmt.Sprint(enc.Fields)
+}
+
+// flush summarizes and forgets every message whose window ended before now,
+// a zero now flushes all of them
+func (s *suppressor) flush(now time.Time) {
+	var expired []*repeatState
+	s.mu.Lock()
+	for key, st := range s.seen {
+		if now.IsZero() || now.Sub(st.ent.Time) >= s.interval {
+			expired = append(expired, st)
+			delete(s.seen, key)
+		}
+	}
+	s.mu.Unlock()
+
+	if now.IsZero() {
+		now = time.Now()
+	}
+	for _, st := range expired {
+		st.summarize(now)
+	}
+}
+
+func (s *suppressor) run() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			s.flush(now)
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Close stops the background flusher and writes any pending summaries
+func (s *suppressor) Close() error {
+	s.once.Do(func() {
+		close(s.done)
+		s.flush(time.Time{})
+	})
+	return nil
+}
@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func writeEntry(core zapcore.Core, level zapcore.Level, msg string, t time.Time) {
+	if ce := core.Check(zapcore.Entry{Level: level, Message: msg, Time: t}, nil); ce != nil {
+		ce.Write()
+	}
+}
+
+func TestMessageTemplate(t *testing.T) {
+	assert.Equal(t, "timeout after #ms", messageTemplate("timeout after 31ms"))
+	assert.Equal(t, "user # failed # times", messageTemplate("user 1024 failed 3 times"))
+	assert.Equal(t, "no digits", messageTemplate("no digits"))
+}
+
+func TestSamplerCore(t *testing.T) {
+	obs, logs := observer.New(zapcore.DebugLevel)
+	core := newSamplerCore(obs, &SamplingConfig{
+		Interval:   time.Second,
+		First:      2,
+		Thereafter: 3,
+		Levels: map[string]SamplingRule{
+			"warn": {First: 1},
+		},
+	})
+
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		writeEntry(core, zapcore.ErrorLevel, fmt.Sprintf("db timeout after %dms", i), now)
+		writeEntry(core, zapcore.WarnLevel, "slow request", now)
+	}
+	// errors: 1, 2, 5, 8 ; warns: 1
+	assert.Equal(t, 4, logs.FilterLevelExact(zapcore.ErrorLevel).Len())
+	assert.Equal(t, 1, logs.FilterLevelExact(zapcore.WarnLevel).Len())
+
+	// a new window resets the budget
+	writeEntry(core, zapcore.WarnLevel, "slow request", now.Add(time.Second))
+	assert.Equal(t, 2, logs.FilterLevelExact(zapcore.WarnLevel).Len())
+
+	// fields added with With share the counters
+	child := core.With([]zapcore.Field{{Key: "k", Type: zapcore.StringType, String: "v"}})
+	writeEntry(child, zapcore.WarnLevel, "slow request", now.Add(time.Second))
+	assert.Equal(t, 2, logs.FilterLevelExact(zapcore.WarnLevel).Len())
+}
+
+func TestSuppressCore(t *testing.T) {
+	obs, logs := observer.New(zapcore.DebugLevel)
+	core, s := newSuppressCore(obs, &SuppressionConfig{Interval: time.Hour})
+	defer s.Close()
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		writeEntry(core, zapcore.ErrorLevel, "connection refused", now)
+	}
+	writeEntry(core, zapcore.InfoLevel, "other", now)
+	assert.Equal(t, 2, logs.Len())
+
+	// a repeat after the interval writes the summary then the message itself
+	writeEntry(core, zapcore.ErrorLevel, "connection refused", now.Add(time.Hour))
+	entries := logs.TakeAll()
+	assert.Len(t, entries, 4)
+	assert.Equal(t, "message repeated 4 times: connection refused", entries[2].Message)
+	assert.Equal(t, zapcore.ErrorLevel, entries[2].Level)
+	assert.Equal(t, "connection refused", entries[3].Message)
+
+	writeEntry(core, zapcore.ErrorLevel, "connection refused", now.Add(time.Hour))
+	assert.NoError(t, core.Sync())
+	entries = logs.TakeAll()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "message repeated 1 times: connection refused", entries[0].Message)
+}
+
+func TestSuppressCoreFields(t *testing.T) {
+	obs, logs := observer.New(zapcore.DebugLevel)
+	core, s := newSuppressCore(obs, &SuppressionConfig{Interval: time.Hour})
+	defer s.Close()
+
+	now := time.Now()
+	write := func(core zapcore.Core, fields ...zapcore.Field) {
+		ent := zapcore.Entry{Level: zapcore.ErrorLevel, Message: "request failed", Time: now}
+		if ce := core.Check(ent, nil); ce != nil {
+			ce.Write(fields...)
+		}
+	}
+	write(core, zap.String("user", "a"))
+	write(core, zap.String("user", "b"))
+	write(core, zap.String("user", "a"))
+	// fields added with With are part of the key as well
+	write(core.With([]zapcore.Field{zap.String("tenant", "x")}), zap.String("user", "a"))
+	assert.Equal(t, 3, logs.Len())
+
+	assert.NoError(t, core.Sync())
+	entries := logs.TakeAll()
+	assert.Len(t, entries, 4)
+	assert.Equal(t, "message repeated 1 times: request failed", entries[3].Message)
+	assert.Equal(t, map[string]interface{}{"user": "a"}, entries[3].ContextMap())
+}
@@ -0,0 +1,333 @@
+package logger
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSyslogCoreUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer pc.Close()
+
+	enc, _ := newEncoder(EncoderConsole)
+	core, err := newSyslogCore(&SyslogConfig{Address: pc.LocalAddr().String(), Tag: "svc"}, "app", enc, zapcore.InfoLevel)
+	assert.NoError(t, err)
+	defer core.Close()
+
+	zap.New(core).Warn("disk almost full", zap.Int("percent", 93))
+
+	buf := make([]byte, 2048)
+	_ = pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	assert.NoError(t, err)
+	msg := string(buf[:n])
+	// local0 (16) * 8 + warning (4)
+	assert.True(t, strings.HasPrefix(msg, "<132>1 "), msg)
+	assert.Contains(t, msg, " svc "+core.pid+" - - ")
+	assert.Contains(t, msg, "disk almost full")
+	assert.Contains(t, msg, `{"percent": 93}`)
+}
+
+func TestSyslogCoreTCPFraming(t *testing.T) {
+	enc, _ := newEncoder(EncoderConsole)
+	core, err := newSyslogCore(&SyslogConfig{Network: "tcp", Address: "127.0.0.1:1"}, "app", enc, zapcore.InfoLevel)
+	assert.NoError(t, err)
+	msg := string(core.format(zapcore.Entry{Level: zapcore.ErrorLevel, Time: time.Now()}, []byte("boom\n")))
+	length, rest, _ := strings.Cut(msg, " ")
+	assert.Equal(t, length, strconv.Itoa(len(rest)))
+	assert.True(t, strings.HasPrefix(rest, "<131>1 "))
+	assert.True(t, strings.HasSuffix(rest, "boom"))
+
+	_, err = newSyslogCore(&SyslogConfig{Network: "sctp", Address: "x"}, "app", enc, zapcore.InfoLevel)
+	assert.Error(t, err)
+}
+
+func TestSyslogCoreHeader(t *testing.T) {
+	enc, _ := newEncoder(EncoderConsole)
+	kern := 0
+	core, err := newSyslogCore(&SyslogConfig{Address: "127.0.0.1:1", Facility: &kern}, "", enc, zapcore.InfoLevel)
+	assert.NoError(t, err)
+	defer core.Close()
+	msg := string(core.format(zapcore.Entry{Level: zapcore.ErrorLevel, Time: time.Now()}, []byte("boom")))
+	// kern (0) * 8 + error (3), and the NILVALUE for the empty APP-NAME
+	assert.True(t, strings.HasPrefix(msg, "<3>1 "), msg)
+	assert.Contains(t, msg, " - "+core.pid+" - - boom")
+
+	for _, facility := range []int{-1, 24} {
+		_, err = newSyslogCore(&SyslogConfig{Address: "127.0.0.1:1", Facility: &facility}, "app", enc, zapcore.InfoLevel)
+		assert.Error(t, err, facility)
+	}
+}
+
+func TestTCPSinkReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+
+	lines := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					lines <- scanner.Text()
+				}
+			}(conn)
+		}
+	}()
+
+	sink, err := newTCPSink(&TCPSinkConfig{Address: ln.Addr().String(), MinBackoff: time.Millisecond})
+	assert.NoError(t, err)
+	defer sink.Close()
+
+	enc, _ := newEncoder(EncoderJSON)
+	log := zap.New(zapcore.NewCore(enc, sink, zapcore.InfoLevel))
+	log.Info("first")
+	assert.Contains(t, <-lines, `"msg":"first"`)
+
+	// a write after losing the connection is dropped and dials again in the background
+	sink.mu.Lock()
+	sink.reset()
+	sink.mu.Unlock()
+	_, err = sink.Write([]byte("dropped\n"))
+	assert.ErrorIs(t, err, errSinkBackoff)
+	assert.Eventually(t, func() bool {
+		sink.mu.Lock()
+		defer sink.mu.Unlock()
+		return sink.conn != nil
+	}, 5*time.Second, time.Millisecond)
+	log.Info("second")
+	assert.Contains(t, <-lines, `"msg":"second"`)
+
+	_, err = newTCPSink(&TCPSinkConfig{})
+	assert.Error(t, err)
+}
+
+func TestReconnectConnBackoff(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+
+	c := newReconnectConn("tcp", addr, time.Second, time.Second, time.Hour, time.Hour)
+	_, err = c.Write([]byte("x"))
+	assert.ErrorIs(t, err, errSinkBackoff)
+	c.mu.Lock()
+	assert.False(t, c.dialing, "no dial while backing off")
+	c.mu.Unlock()
+}
+
+func TestReconnectConnWriteTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+	// the collector accepts but never reads
+	accepted := make(chan net.Conn, 4)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+
+	c := newReconnectConn("tcp", ln.Addr().String(), time.Second, 50*time.Millisecond, time.Hour, time.Hour)
+	defer c.Close()
+	chunk := make([]byte, 1<<20)
+	start := time.Now()
+	for err == nil {
+		_, err = c.Write(chunk)
+	}
+	assert.Less(t, time.Since(start), 5*time.Second)
+	var netErr net.Error
+	assert.True(t, errors.As(err, &netErr) && netErr.Timeout(), err)
+
+	// the stalled connection was dropped and a new one is dialed in the background
+	assert.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.conn != nil
+	}, 5*time.Second, time.Millisecond)
+	for len(accepted) > 0 {
+		(<-accepted).Close()
+	}
+}
+
+type batchServer struct {
+	mu      sync.Mutex
+	lines   []string
+	fail    atomic.Bool
+	headers http.Header
+}
+
+func (b *batchServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if b.fail.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	gz, err := gzip.NewReader(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	data, _ := io.ReadAll(gz)
+	b.mu.Lock()
+	b.headers = r.Header.Clone()
+	b.lines = append(b.lines, strings.Split(strings.TrimSpace(string(data)), "\n")...)
+	b.mu.Unlock()
+}
+
+func (b *batchServer) received() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.lines...)
+}
+
+func TestHTTPSinkBatching(t *testing.T) {
+	srv := &batchServer{}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	sink, err := newHTTPSink(&HTTPSinkConfig{
+		URL:           ts.URL,
+		Headers:       map[string]string{"Authorization": "Bearer t"},
+		BatchBytes:    1 << 20,
+		BatchInterval: 20 * time.Millisecond,
+	})
+	assert.NoError(t, err)
+
+	for _, line := range []string{"a", "b", "c"} {
+		_, _ = sink.Write([]byte(line + "\n"))
+	}
+	assert.Eventually(t, func() bool { return len(srv.received()) == 3 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"a", "b", "c"}, srv.received())
+	srv.mu.Lock()
+	assert.Equal(t, "Bearer t", srv.headers.Get("Authorization"))
+	assert.Equal(t, "application/x-ndjson", srv.headers.Get("Content-Type"))
+	srv.mu.Unlock()
+
+	_, _ = sink.Write([]byte("d\n"))
+	assert.NoError(t, sink.Close())
+	assert.Equal(t, []string{"a", "b", "c", "d"}, srv.received())
+
+	n, err := sink.Write([]byte("e\n"))
+	assert.Equal(t, 0, n)
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
+
+func TestHTTPSinkSpill(t *testing.T) {
+	srv := &batchServer{}
+	srv.fail.Store(true)
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	spillDir := filepath.Join(t.TempDir(), "spill")
+	sink, err := newHTTPSink(&HTTPSinkConfig{
+		URL:           ts.URL,
+		BatchBytes:    2,
+		BatchInterval: time.Hour,
+		MaxRetries:    1,
+		RetryBackoff:  time.Millisecond,
+		SpillDir:      spillDir,
+	})
+	assert.NoError(t, err)
+	defer sink.Close()
+
+	_, _ = sink.Write([]byte("lost?\n"))
+	assert.Eventually(t, func() bool {
+		files, _ := os.ReadDir(spillDir)
+		return len(files) == 1 && strings.HasSuffix(files[0].Name(), ".ndjson")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the spilled batch follows the next successful send
+	srv.fail.Store(false)
+	_, _ = sink.Write([]byte("ok\n"))
+	assert.Eventually(t, func() bool { return len(srv.received()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"ok", "lost?"}, srv.received())
+	files, _ := os.ReadDir(spillDir)
+	assert.Empty(t, files)
+}
+
+func TestHTTPSinkSpillLimits(t *testing.T) {
+	spillDir := filepath.Join(t.TempDir(), "spill")
+	sink, err := newHTTPSink(&HTTPSinkConfig{
+		URL:           "http://127.0.0.1:0",
+		BatchInterval: time.Hour,
+		SpillDir:      spillDir,
+		MaxSpillBytes: 8,
+		MaxSpillFiles: 3,
+	})
+	assert.NoError(t, err)
+	defer sink.Close()
+
+	read := func() []string {
+		files, _ := filepath.Glob(filepath.Join(spillDir, "*.ndjson"))
+		sort.Strings(files)
+		var batches []string
+		for _, f := range files {
+			b, _ := os.ReadFile(f)
+			batches = append(batches, string(b))
+		}
+		return batches
+	}
+
+	// the file count keeps the newest three
+	for _, b := range []string{"a\n", "b\n", "c\n", "d\n"} {
+		sink.spill([]byte(b))
+	}
+	assert.Equal(t, []string{"b\n", "c\n", "d\n"}, read())
+
+	// the byte cap deletes the oldest batches first
+	sink.spill([]byte("eeee\n"))
+	assert.Equal(t, []string{"d\n", "eeee\n"}, read())
+}
+
+func TestInitLoggerNetworkOutputs(t *testing.T) {
+	config := DefaultConfig()
+	config.FileDir = t.TempDir()
+	config.Outputs = []OutputConfig{{Type: OutputSyslog}}
+	assert.Error(t, InitLogger(config))
+	config.Outputs = []OutputConfig{{Type: OutputHTTP, HTTP: &HTTPSinkConfig{}}}
+	assert.Error(t, InitLogger(config))
+
+	srv := &batchServer{}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	config.Outputs = []OutputConfig{{Type: OutputHTTP, HTTP: &HTTPSinkConfig{URL: ts.URL}}}
+	assert.NoError(t, InitLogger(config))
+	Infof("shipped %d", 1)
+	assert.NoError(t, Close())
+
+	lines := srv.received()
+	assert.Len(t, lines, 1)
+	var entry map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "shipped 1", entry["msg"])
+}
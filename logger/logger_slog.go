@@ -0,0 +1,306 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// slog levels matching the Trace and Notice helpers of Logger
+const (
+	LevelTrace  = slog.Level(-8)
+	LevelNotice = slog.Level(2)
+)
+
+// zapLevel maps a slog level to the zap level used by the cores,
+// Trace is written as debug and Notice as info like the Logger helpers do
+func zapLevel(level slog.Level) zapcore.Level {
+	switch {
+	case level < slog.LevelInfo:
+		return zapcore.DebugLevel
+	case level < slog.LevelWarn:
+		return zapcore.InfoLevel
+	case level < slog.LevelError:
+		return zapcore.WarnLevel
+	}
+	return zapcore.ErrorLevel
+}
+
+// slogLevel maps a zap level to slog, levels above error keep their distance to it
+func slogLevel(level zapcore.Level) slog.Level {
+	switch level {
+	case zapcore.DebugLevel:
+		return slog.LevelDebug
+	case zapcore.InfoLevel:
+		return slog.LevelInfo
+	case zapcore.WarnLevel:
+		return slog.LevelWarn
+	}
+	return slog.LevelError + slog.Level(level-zapcore.ErrorLevel)
+}
+
+// SlogHandler is a slog.Handler writing records to the cores of a Logger
+type SlogHandler struct {
+	// nil means the DefaultLogger at the time a record is handled
+	logger *Logger
+	fields []zapcore.Field
+	// groups opened by WithGroup that have no attributes yet
+	groups []string
+}
+
+// NewSlogHandler returns a slog.Handler backed by l, or by DefaultLogger when l is nil;
+// trace and user IDs and the other fields carried by the context are added to every record
+func NewSlogHandler(l *Logger) *SlogHandler {
+	return &SlogHandler{logger: l}
+}
+
+// NewSlogLogger returns a slog.Logger backed by l, see NewSlogHandler
+func NewSlogLogger(l *Logger) *slog.Logger {
+	return slog.New(NewSlogHandler(l))
+}
+
+func (h *SlogHandler) core() zapcore.Core {
+	l := h.logger
+	if l == nil {
+		l = DefaultLogger
+	}
+	return l.Desugar().Core()
+}
+
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.core().Enabled(zapLevel(level))
+}
+
+func (h *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	ent := zapcore.Entry{
+		Level:   zapLevel(record.Level),
+		Time:    record.Time,
+		Message: record.Message,
+	}
+	if record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		ent.Caller = zapcore.EntryCaller{
+			Defined:  true,
+			PC:       frame.PC,
+			File:     frame.File,
+			Line:     frame.Line,
+			Function: frame.Function,
+		}
+	}
+
+	ce := h.core().Check(ent, nil)
+	if ce == nil {
+		return nil
+	}
+
+	var fields []zapcore.Field
+	if ctx != nil {
+		fields = sweetenFields(contextFields(ctx))
+	}
+	fields = append(fields, h.fields...)
+	if record.NumAttrs() > 0 {
+		for _, g := range h.groups {
+			fields = append(fields, zap.Namespace(g))
+		}
+		record.Attrs(func(a slog.Attr) bool {
+			fields = appendAttr(fields, a)
+			return true
+		})
+	}
+	ce.Write(fields...)
+	return nil
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	clone := *h
+	clone.fields = append([]zapcore.Field(nil), h.fields...)
+	for _, g := range h.groups {
+		clone.fields = append(clone.fields, zap.Namespace(g))
+	}
+	clone.groups = nil
+	for _, a := range attrs {
+		clone.fields = appendAttr(clone.fields, a)
+	}
+	return &clone
+}
+
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.groups = append(append([]string(nil), h.groups...), name)
+	return &clone
+}
+
+// sweetenFields converts loosely typed key-value pairs to fields
+func sweetenFields(keysAndValues []interface{}) []zapcore.Field {
+	fields := make([]zapcore.Field, 0, len(keysAndValues)/2)
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			continue
+		}
+		fields = append(fields, zap.Any(key, keysAndValues[i+1]))
+	}
+	return fields
+}
+
+// appendAttr converts a to fields following the slog handler rules:
+// empty attributes are dropped and groups without a key are inlined
+func appendAttr(fields []zapcore.Field, a slog.Attr) []zapcore.Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return fields
+		}
+		if a.Key == "" {
+			for _, ga := range attrs {
+				fields = appendAttr(fields, ga)
+			}
+			return fields
+		}
+		return append(fields, zap.Object(a.Key, slogGroup(attrs)))
+	case slog.KindString:
+		return append(fields, zap.String(a.Key, a.Value.String()))
+	case slog.KindInt64:
+		return append(fields, zap.Int64(a.Key, a.Value.Int64()))
+	case slog.KindUint64:
+		return append(fields, zap.Uint64(a.Key, a.Value.Uint64()))
+	case slog.KindFloat64:
+		return append(fields, zap.Float64(a.Key, a.Value.Float64()))
+	case slog.KindBool:
+		return append(fields, zap.Bool(a.Key, a.Value.Bool()))
+	case slog.KindDuration:
+		return append(fields, zap.Duration(a.Key, a.Value.Duration()))
+	case slog.KindTime:
+		return append(fields, zap.Time(a.Key, a.Value.Time()))
+	}
+
+	if err, ok := a.Value.Any().(error); ok {
+		return append(fields, zap.NamedError(a.Key, err))
+	}
+	return append(fields, zap.Any(a.Key, a.Value.Any()))
+}
+
+// slogGroup marshals the attributes of a group as a nested object
+type slogGroup []slog.Attr
+
+func (g slogGroup) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	var fields []zapcore.Field
+	for _, a := range g {
+		fields = appendAttr(fields, a)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return nil
+}
+
+// slogCore is a zapcore.Core forwarding entries to a slog.Handler
+type slogCore struct {
+	handler slog.Handler
+}
+
+// NewSlogCore returns a zapcore.Core that forwards every entry to h
+func NewSlogCore(h slog.Handler) zapcore.Core {
+	return &slogCore{handler: h}
+}
+
+// UseSlogHandler makes DefaultLogger forward every entry to h
+func UseSlogHandler(h slog.Handler) {
+	_ = closeResources()
+	zapLogger := zap.New(NewSlogCore(h),
+		zap.AddCaller(),
+		zap.AddCallerSkip(1), // caller skip
+	)
+	DefaultLogger = &Logger{
+		SugaredLogger: zapLogger.Sugar(),
+	}
+}
+
+func (c *slogCore) Enabled(level zapcore.Level) bool {
+	return c.handler.Enabled(context.Background(), slogLevel(level))
+}
+
+func (c *slogCore) With(fields []zapcore.Field) zapcore.Core {
+	return &slogCore{handler: withFields(c.handler, fields)}
+}
+
+// withFields adds fields to h, a namespace field turns the fields after it into a group
+func withFields(h slog.Handler, fields []zapcore.Field) slog.Handler {
+	for i, f := range fields {
+		if f.Type == zapcore.NamespaceType {
+			h = h.WithAttrs(fieldsToAttrs(fields[:i])).WithGroup(f.Key)
+			return withFields(h, fields[i+1:])
+		}
+	}
+	return h.WithAttrs(fieldsToAttrs(fields))
+}
+
+// fieldsToAttrs converts fields to attributes, nesting those following a namespace
+func fieldsToAttrs(fields []zapcore.Field) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for i, f := range fields {
+		if f.Type == zapcore.NamespaceType {
+			return append(attrs, slog.Attr{Key: f.Key, Value: slog.GroupValue(fieldsToAttrs(fields[i+1:])...)})
+		}
+		enc := zapcore.NewMapObjectEncoder()
+		f.AddTo(enc)
+		for k, v := range enc.Fields {
+			attrs = append(attrs, slog.Any(k, v))
+		}
+	}
+	return attrs
+}
+
+func (c *slogCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// callerPC returns a program counter from which slog resolves caller, or 0
+// when caller was inlined into another function and cannot be expressed as one
+func callerPC(caller zapcore.EntryCaller) uintptr {
+	if !caller.Defined || caller.PC == 0 {
+		return 0
+	}
+	// slog expects a return address, zap records the call instruction
+	pc := caller.PC + 1
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if frame.File != caller.File || frame.Line != caller.Line {
+		return 0
+	}
+	return pc
+}
+
+func (c *slogCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	pc := callerPC(ent.Caller)
+	record := slog.NewRecord(ent.Time, slogLevel(ent.Level), ent.Message, pc)
+	if pc == 0 && ent.Caller.Defined {
+		record.AddAttrs(slog.String("caller", ent.Caller.String()))
+	}
+	if ent.LoggerName != "" {
+		record.AddAttrs(slog.String("logger", ent.LoggerName))
+	}
+	record.AddAttrs(fieldsToAttrs(fields)...)
+	return c.handler.Handle(context.Background(), record)
+}
+
+func (c *slogCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSlogHandler(t *testing.T) {
+	observeDefaultLogger(t)
+	core, logs := observer.New(zapcore.DebugLevel)
+	log := NewSlogLogger(&Logger{SugaredLogger: zap.New(core).Sugar()})
+
+	ctx := WithCtxFields(WithTraceID(context.Background(), "t-1"), "tenant", "acme")
+	log.With("svc", "api").WithGroup("req").With("method", "GET").
+		InfoContext(ctx, "handled", "status", 200, slog.Group("timing", "total", time.Second), "err", errors.New("boom"))
+	log.Log(ctx, LevelTrace, "trace")
+	log.Log(ctx, LevelNotice, "notice")
+	log.WithGroup("empty").Warn("no attrs")
+
+	entries := logs.TakeAll()
+	assert.Len(t, entries, 4)
+	assert.Equal(t, "handled", entries[0].Message)
+	assert.True(t, entries[0].Caller.Defined)
+	assert.Contains(t, entries[0].Caller.File, "logger_slog_test.go")
+	assert.Equal(t, map[string]interface{}{
+		"trace_id": "t-1",
+		"tenant":   "acme",
+		"svc":      "api",
+		"req": map[string]interface{}{
+			"method": "GET",
+			"status": int64(200),
+			"timing": map[string]interface{}{"total": time.Second},
+			"err":    "boom",
+		},
+	}, entries[0].ContextMap())
+
+	assert.Equal(t, zapcore.DebugLevel, entries[1].Level)
+	assert.Equal(t, zapcore.InfoLevel, entries[2].Level)
+	assert.Equal(t, zapcore.WarnLevel, entries[3].Level)
+	assert.NotContains(t, entries[3].ContextMap(), "empty")
+}
+
+func TestSlogHandlerEnabled(t *testing.T) {
+	core, _ := observer.New(zapcore.WarnLevel)
+	h := NewSlogHandler(&Logger{SugaredLogger: zap.New(core).Sugar()})
+	assert.False(t, h.Enabled(context.Background(), LevelNotice))
+	assert.True(t, h.Enabled(context.Background(), slog.LevelWarn))
+	assert.True(t, h.Enabled(context.Background(), slog.LevelError+4))
+}
+
+func TestUseSlogHandler(t *testing.T) {
+	old := DefaultLogger
+	defer func() { DefaultLogger = old }()
+
+	var buf bytes.Buffer
+	UseSlogHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo, AddSource: true}))
+
+	Debugf("hidden")
+	Warnf("forwarded %d", 1)
+
+	var record map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "WARN", record["level"])
+	assert.Equal(t, "forwarded 1", record["msg"])
+	// inlined wrappers cannot be resolved from a single pc, caller is written instead
+	caller, ok := record["caller"].(string)
+	if !ok {
+		caller, _ = record["source"].(map[string]any)["file"].(string)
+	}
+	assert.Contains(t, caller, "logger_slog_test.go")
+}
+
+func TestSlogCoreFields(t *testing.T) {
+	var buf bytes.Buffer
+	core := NewSlogCore(slog.NewJSONHandler(&buf, nil))
+	zap.New(core).With(zap.Int("order", 7), zap.Namespace("user"), zap.String("id", "u-1")).
+		Error("failed", zap.Bool("retry", true))
+
+	var record map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "ERROR", record["level"])
+	assert.Equal(t, float64(7), record["order"])
+	assert.Equal(t, map[string]any{"id": "u-1", "retry": true}, record["user"])
+}
@@ -0,0 +1,170 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// SyslogConfig holds the settings of a syslog output
+type SyslogConfig struct {
+	// Network: udp (default), tcp or unix
+	Network string
+	// Address such as 127.0.0.1:514, or a socket path like /dev/log for unix
+	Address string
+	// Facility code from 0 (kern) to 23 (local7), nil defaults to 16 (local0)
+	Facility *int
+	// APP-NAME of the messages, defaults to LogConfig.AppName
+	Tag string
+}
+
+const (
+	defaultSyslogFacility = 16
+	maxSyslogFacility     = 23
+)
+
+// syslogNil is the RFC 5424 NILVALUE used for empty header fields
+const syslogNil = "-"
+
+// syslogSeverity maps zap levels to RFC 5424 severities
+func syslogSeverity(level zapcore.Level) int {
+	switch level {
+	case zapcore.DebugLevel:
+		return 7
+	case zapcore.InfoLevel:
+		return 6
+	case zapcore.WarnLevel:
+		return 4
+	case zapcore.ErrorLevel:
+		return 3
+	case zapcore.DPanicLevel:
+		return 2
+	case zapcore.PanicLevel:
+		return 1
+	case zapcore.FatalLevel:
+		return 0
+	}
+	return 5
+}
+
+// syslogCore writes RFC 5424 messages whose MSG part is produced by enc
+type syslogCore struct {
+	zapcore.LevelEnabler
+	enc      zapcore.Encoder
+	out      *reconnectConn
+	facility int
+	hostname string
+	tag      string
+	pid      string
+	// stream transports need RFC 6587 octet-counting framing
+	framed bool
+}
+
+func newSyslogCore(cfg *SyslogConfig, appName string, enc zapcore.Encoder, enab zapcore.LevelEnabler) (*syslogCore, error) {
+	if cfg == nil || cfg.Address == "" {
+		return nil, errors.New("logger: syslog output requires an address")
+	}
+
+	network := cfg.Network
+	switch network {
+	case "", "udp":
+		network = "udp"
+	case "tcp":
+	case "unix":
+		// /dev/log style sockets are datagram based
+		network = "unixgram"
+	default:
+		return nil, errors.New("logger: unknown syslog network " + strconv.Quote(cfg.Network))
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = syslogNil
+	}
+	facility := defaultSyslogFacility
+	if cfg.Facility != nil {
+		facility = *cfg.Facility
+		if facility < 0 || facility > maxSyslogFacility {
+			return nil, fmt.Errorf("logger: syslog facility %d out of range 0-%d", facility, maxSyslogFacility)
+		}
+	}
+	tag := cfg.Tag
+	if tag == "" {
+		tag = appName
+	}
+	if tag == "" {
+		tag = syslogNil
+	}
+
+	return &syslogCore{
+		LevelEnabler: enab,
+		enc:          enc,
+		out:          newReconnectConn(network, cfg.Address, 0, 0, 0, 0),
+		facility:     facility,
+		hostname:     hostname,
+		tag:          tag,
+		pid:          strconv.Itoa(os.Getpid()),
+		framed:       network == "tcp",
+	}, nil
+}
+
+func (c *syslogCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.enc = c.enc.Clone()
+	for i := range fields {
+		fields[i].AddTo(clone.enc)
+	}
+	return &clone
+}
+
+func (c *syslogCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// format builds the RFC 5424 message for ent with msg as MSG part
+func (c *syslogCore) format(ent zapcore.Entry, msg []byte) []byte {
+	var b bytes.Buffer
+	b.WriteByte('<')
+	b.WriteString(strconv.Itoa(c.facility*8 + syslogSeverity(ent.Level)))
+	b.WriteString(">1 ")
+	b.WriteString(ent.Time.Format(time.RFC3339Nano))
+	b.WriteByte(' ')
+	b.WriteString(c.hostname)
+	b.WriteByte(' ')
+	b.WriteString(c.tag)
+	b.WriteByte(' ')
+	b.WriteString(c.pid)
+	b.WriteString(" - - ")
+	b.Write(bytes.TrimRight(msg, "\r\n"))
+
+	if !c.framed {
+		return b.Bytes()
+	}
+	return append([]byte(strconv.Itoa(b.Len())+" "), b.Bytes()...)
+}
+
+func (c *syslogCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	_, err = c.out.Write(c.format(ent, buf.Bytes()))
+	buf.Free()
+	return err
+}
+
+func (c *syslogCore) Sync() error {
+	return c.out.Sync()
+}
+
+func (c *syslogCore) Close() error {
+	return c.out.Close()
+}
@@ -2,6 +2,8 @@ package logger
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 
 	"go.uber.org/zap"
 )
@@ -18,13 +20,28 @@ const (
 	ContextKeyLogger = "logger" // logger context key
 )
 
+// ctxFieldsKey stores the fields accumulated by WithCtxFields
+type ctxFieldsKey struct{}
+
+// contextKeyField maps a context key to the log field its value is written to
+type contextKeyField struct {
+	key   any
+	field string
+}
+
+var (
+	registryMu sync.Mutex
+	// copy-on-write list read by FromContext on every call
+	contextKeyRegistry atomic.Pointer[[]contextKeyField]
+)
+
 // WithTraceID adds traceID to context and returns a new context with logger
 func WithTraceID(ctx context.Context, traceID string) context.Context {
 	// Add traceID to context
 	ctx = context.WithValue(ctx, TraceIDKey, traceID)
 
 	// Get existing logger or create new one
-	logger := FromContext(ctx)
+	logger := loggerFromContext(ctx)
 
 	// Add traceID to logger
 	logger = &Logger{
@@ -41,7 +58,7 @@ func WithUserID(ctx context.Context, userID string) context.Context {
 	ctx = context.WithValue(ctx, userIDKey, userID)
 
 	// Get existing logger or create new one
-	logger := FromContext(ctx)
+	logger := loggerFromContext(ctx)
 
 	// Add userID to logger
 	logger = &Logger{
@@ -60,8 +77,86 @@ func WithContext(ctx context.Context, traceID string, userID string) context.Con
 	return WithUserID(ctx, userID)
 }
 
-// FromContext retrieves logger from context
-func FromContext(ctx context.Context) *Logger {
+// WithCtxFields adds key-value pairs to context, they are written by every logger obtained from it
+func WithCtxFields(ctx context.Context, keysAndValues ...interface{}) context.Context {
+	if len(keysAndValues) == 0 {
+		return ctx
+	}
+
+	// Accumulate fields, never appending in place to the parent's slice
+	prev := GetCtxFields(ctx)
+	fields := make([]interface{}, 0, len(prev)+len(keysAndValues))
+	fields = append(append(fields, prev...), keysAndValues...)
+	ctx = context.WithValue(ctx, ctxFieldsKey{}, fields)
+
+	// Add fields to logger
+	logger := &Logger{
+		SugaredLogger: loggerFromContext(ctx).With(keysAndValues...),
+	}
+
+	// Add logger to context
+	return context.WithValue(ctx, ContextKeyLogger, logger)
+}
+
+// GetCtxFields gets the key-value pairs added by WithCtxFields
+func GetCtxFields(ctx context.Context) []interface{} {
+	fields, _ := ctx.Value(ctxFieldsKey{}).([]interface{})
+	return fields
+}
+
+// RegisterContextKey makes FromContext write the value stored under key as field,
+// for values put into the context by code unaware of the logger
+func RegisterContextKey(key any, field string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	var keys []contextKeyField
+	if old := contextKeyRegistry.Load(); old != nil {
+		for _, k := range *old {
+			if k.key != key {
+				keys = append(keys, k)
+			}
+		}
+	}
+	keys = append(keys, contextKeyField{key: key, field: field})
+	contextKeyRegistry.Store(&keys)
+}
+
+// UnregisterContextKey removes key added by RegisterContextKey
+func UnregisterContextKey(key any) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	old := contextKeyRegistry.Load()
+	if old == nil {
+		return
+	}
+	var keys []contextKeyField
+	for _, k := range *old {
+		if k.key != key {
+			keys = append(keys, k)
+		}
+	}
+	contextKeyRegistry.Store(&keys)
+}
+
+// registeredFields returns the fields of registered keys present in ctx
+func registeredFields(ctx context.Context) []interface{} {
+	keys := contextKeyRegistry.Load()
+	if keys == nil {
+		return nil
+	}
+	var fields []interface{}
+	for _, k := range *keys {
+		if v := ctx.Value(k.key); v != nil {
+			fields = append(fields, k.field, v)
+		}
+	}
+	return fields
+}
+
+// loggerFromContext retrieves the logger stored in context without registered fields
+func loggerFromContext(ctx context.Context) *Logger {
 	if l, ok := ctx.Value(ContextKeyLogger).(*Logger); ok {
 		return l
 	}
@@ -69,6 +164,33 @@ func FromContext(ctx context.Context) *Logger {
 	return DefaultLogger
 }
 
+// FromContext retrieves logger from context, adding the values of registered context keys
+func FromContext(ctx context.Context) *Logger {
+	l := loggerFromContext(ctx)
+	if fields := registeredFields(ctx); len(fields) > 0 {
+		return &Logger{SugaredLogger: l.With(fields...), ctx: ctx}
+	}
+	if _, ok := ctx.Value(ContextKeyLogger).(*Logger); !ok {
+		// the default logger knows nothing of values put into ctx directly
+		return l
+	}
+	return &Logger{SugaredLogger: l.SugaredLogger, ctx: ctx}
+}
+
+// contextFields returns every field carried by ctx: trace and user IDs,
+// fields added by WithCtxFields and the values of registered keys
+func contextFields(ctx context.Context) []interface{} {
+	var fields []interface{}
+	if traceID := GetTraceID(ctx); traceID != "" {
+		fields = append(fields, string(TraceIDKey), traceID)
+	}
+	if userID := GetUserID(ctx); userID != "" {
+		fields = append(fields, string(userIDKey), userID)
+	}
+	fields = append(fields, GetCtxFields(ctx)...)
+	return append(fields, registeredFields(ctx)...)
+}
+
 // GetTraceID gets traceID from context
 func GetTraceID(ctx context.Context) string {
 	if traceID, ok := ctx.Value(TraceIDKey).(string); ok {
@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type tenantKey struct{}
+
+func TestWithCtxFields(t *testing.T) {
+	logs := observeDefaultLogger(t)
+
+	parent := WithContext(context.Background(), "t-1", "u-1")
+	parent = WithCtxFields(parent, "tenant", "acme")
+	child1 := WithCtxFields(parent, "job_id", 1)
+	child2 := WithCtxFields(parent, "path", "/orders")
+
+	assert.Equal(t, []interface{}{"tenant", "acme", "job_id", 1}, GetCtxFields(child1))
+	assert.Equal(t, []interface{}{"tenant", "acme", "path", "/orders"}, GetCtxFields(child2))
+	assert.Same(t, parent, WithCtxFields(parent))
+
+	CtxInfof(child1, "run %s", "a")
+	CtxWarnf(child2, "slow")
+
+	entries := logs.TakeAll()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "run a", entries[0].Message)
+	assert.Equal(t, map[string]interface{}{
+		"trace_id": "t-1", "user_id": "u-1", "tenant": "acme", "job_id": int64(1),
+	}, entries[0].ContextMap())
+	assert.Equal(t, zapcore.WarnLevel, entries[1].Level)
+	assert.Equal(t, "/orders", entries[1].ContextMap()["path"])
+	assert.NotContains(t, entries[1].ContextMap(), "job_id")
+}
+
+func TestRegisterContextKey(t *testing.T) {
+	logs := observeDefaultLogger(t)
+	RegisterContextKey(tenantKey{}, "tenant")
+	defer UnregisterContextKey(tenantKey{})
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	CtxErrorf(ctx, "failed")
+	CtxInfof(context.Background(), "no tenant")
+
+	entries := logs.TakeAll()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "acme", entries[0].ContextMap()["tenant"])
+	assert.Empty(t, entries[1].ContextMap())
+
+	UnregisterContextKey(tenantKey{})
+	CtxInfof(ctx, "unregistered")
+	assert.Empty(t, logs.TakeAll()[0].ContextMap())
+}
+
+func TestLoggerCtxMethods(t *testing.T) {
+	observeDefaultLogger(t)
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := &Logger{SugaredLogger: zap.New(core).Sugar().With("component", "worker")}
+
+	// methods log through their receiver, not through the logger stored in ctx
+	ctx := WithCtxFields(WithTraceID(context.Background(), "t-2"), "job_id", "j-9")
+	l.CtxInfof(ctx, "done %d", 3)
+	l.CtxDebugf(context.Background(), "plain")
+
+	entries := logs.TakeAll()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "done 3", entries[0].Message)
+	assert.Equal(t, map[string]interface{}{
+		"component": "worker", "trace_id": "t-2", "job_id": "j-9",
+	}, entries[0].ContextMap())
+	assert.Equal(t, map[string]interface{}{"component": "worker"}, entries[1].ContextMap())
+}
+
+func TestFromContextCtxMethods(t *testing.T) {
+	logs := observeDefaultLogger(t)
+	RegisterContextKey(tenantKey{}, "tenant")
+	defer UnregisterContextKey(tenantKey{})
+
+	ctx := context.WithValue(WithTraceID(context.Background(), "t-1"), tenantKey{}, "acme")
+	FromContext(ctx).CtxInfof(ctx, "registered")
+	ctx = WithTraceID(context.Background(), "t-2")
+	FromContext(ctx).CtxInfof(ctx, "stored")
+	FromContext(ctx).WithContext(ctx).CtxWarnf(ctx, "twice")
+
+	// a different ctx still adds its fields
+	other := WithUserID(context.Background(), "u-1")
+	FromContext(ctx).CtxErrorf(other, "other")
+
+	entries := logs.TakeAll()
+	assert.Len(t, entries, 4)
+	keys := func(e observer.LoggedEntry) []string {
+		var keys []string
+		for _, f := range e.Context {
+			keys = append(keys, f.Key)
+		}
+		return keys
+	}
+	assert.Equal(t, []string{"trace_id", "tenant"}, keys(entries[0]))
+	assert.Equal(t, []string{"trace_id"}, keys(entries[1]))
+	assert.Equal(t, []string{"trace_id"}, keys(entries[2]))
+	assert.Equal(t, []string{"trace_id", "user_id"}, keys(entries[3]))
+}
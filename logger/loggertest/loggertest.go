@@ -0,0 +1,216 @@
+// Package loggertest captures what code logs through the logger package so tests can assert on it.
+package loggertest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Jsharkc/mygopkg/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// Entry is a captured log entry
+type Entry struct {
+	Time    time.Time
+	Level   zapcore.Level
+	Message string
+	Caller  zapcore.EntryCaller
+	// Fields holds every field of the entry, including trace and user IDs
+	Fields  map[string]interface{}
+	TraceID string
+	UserID  string
+}
+
+// String formats the entry for failure messages
+func (e Entry) String() string {
+	return fmt.Sprintf("%s %q %v", e.Level.CapitalString(), e.Message, e.Fields)
+}
+
+// Recorder keeps the entries written while it is installed as logger.DefaultLogger
+type Recorder struct {
+	// Logger is the in-memory logger, also installed as logger.DefaultLogger
+	Logger *logger.Logger
+	logs   *observer.ObservedLogs
+}
+
+var (
+	mu      sync.Mutex
+	current *Recorder
+)
+
+// New installs an in-memory logger capturing entries of every level as logger.DefaultLogger,
+// the previous logger is restored when the test ends
+func New(t testing.TB) *Recorder {
+	return NewWithLevel(t, zapcore.DebugLevel)
+}
+
+// NewWithLevel is like New but only captures entries at or above level
+func NewWithLevel(t testing.TB, level zapcore.LevelEnabler) *Recorder {
+	t.Helper()
+
+	core, logs := observer.New(level)
+	r := &Recorder{
+		Logger: &logger.Logger{
+			SugaredLogger: zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1)).Sugar(),
+		},
+		logs: logs,
+	}
+
+	mu.Lock()
+	prevDefault, prevCurrent := logger.DefaultLogger, current
+	logger.DefaultLogger, current = r.Logger, r
+	mu.Unlock()
+
+	t.Cleanup(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		logger.DefaultLogger, current = prevDefault, prevCurrent
+	})
+	return r
+}
+
+func newEntry(e observer.LoggedEntry) Entry {
+	fields := e.ContextMap()
+	entry := Entry{
+		Time:    e.Time,
+		Level:   e.Level,
+		Message: e.Message,
+		Caller:  e.Caller,
+		Fields:  fields,
+	}
+	entry.TraceID, _ = fields["trace_id"].(string)
+	entry.UserID, _ = fields["user_id"].(string)
+	return entry
+}
+
+// Entries returns every captured entry in logging order
+func (r *Recorder) Entries() []Entry {
+	logged := r.logs.All()
+	entries := make([]Entry, len(logged))
+	for i, e := range logged {
+		entries[i] = newEntry(e)
+	}
+	return entries
+}
+
+// Filter returns the captured entries for which keep returns true
+func (r *Recorder) Filter(keep func(Entry) bool) []Entry {
+	var entries []Entry
+	for _, e := range r.Entries() {
+		if keep(e) {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// Find returns the entries at level whose message contains substr
+func (r *Recorder) Find(level zapcore.Level, substr string) []Entry {
+	return r.Filter(func(e Entry) bool {
+		return e.Level == level && strings.Contains(e.Message, substr)
+	})
+}
+
+// Messages returns the messages of the captured entries
+func (r *Recorder) Messages() []string {
+	entries := r.Entries()
+	messages := make([]string, len(entries))
+	for i, e := range entries {
+		messages[i] = e.Message
+	}
+	return messages
+}
+
+// Len returns the number of captured entries
+func (r *Recorder) Len() int {
+	return r.logs.Len()
+}
+
+// Reset forgets the captured entries
+func (r *Recorder) Reset() {
+	r.logs.TakeAll()
+}
+
+// AssertLogged reports an error unless an entry at level with a message containing substr was captured
+func (r *Recorder) AssertLogged(t testing.TB, level zapcore.Level, substr string) bool {
+	t.Helper()
+	if len(r.Find(level, substr)) > 0 {
+		return true
+	}
+	t.Errorf("no %s entry containing %q was logged, got:%s", level.CapitalString(), substr, r.dump())
+	return false
+}
+
+// AssertNotLogged reports an error if an entry at level with a message containing substr was captured
+func (r *Recorder) AssertNotLogged(t testing.TB, level zapcore.Level, substr string) bool {
+	t.Helper()
+	found := r.Find(level, substr)
+	if len(found) == 0 {
+		return true
+	}
+	t.Errorf("unexpected %s entry containing %q: %s", level.CapitalString(), substr, found[0])
+	return false
+}
+
+// AssertField reports an error unless an entry with a message containing substr has field key set to value
+func (r *Recorder) AssertField(t testing.TB, substr, key string, value interface{}) bool {
+	t.Helper()
+	for _, e := range r.Entries() {
+		if strings.Contains(e.Message, substr) && fmt.Sprint(e.Fields[key]) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	t.Errorf("no entry containing %q has %s=%v, got:%s", substr, key, value, r.dump())
+	return false
+}
+
+func (r *Recorder) dump() string {
+	var b strings.Builder
+	for _, e := range r.Entries() {
+		b.WriteString("\n\t")
+		b.WriteString(e.String())
+	}
+	if b.Len() == 0 {
+		return " nothing"
+	}
+	return b.String()
+}
+
+// Current returns the recorder installed by the latest New still in effect
+func Current() *Recorder {
+	mu.Lock()
+	defer mu.Unlock()
+	return current
+}
+
+// AssertLogged is Recorder.AssertLogged on the current recorder
+func AssertLogged(t testing.TB, level zapcore.Level, substr string) bool {
+	t.Helper()
+	return mustCurrent(t).AssertLogged(t, level, substr)
+}
+
+// AssertNotLogged is Recorder.AssertNotLogged on the current recorder
+func AssertNotLogged(t testing.TB, level zapcore.Level, substr string) bool {
+	t.Helper()
+	return mustCurrent(t).AssertNotLogged(t, level, substr)
+}
+
+// AssertField is Recorder.AssertField on the current recorder
+func AssertField(t testing.TB, substr, key string, value interface{}) bool {
+	t.Helper()
+	return mustCurrent(t).AssertField(t, substr, key, value)
+}
+
+func mustCurrent(t testing.TB) *Recorder {
+	t.Helper()
+	r := Current()
+	if r == nil {
+		t.Fatal("loggertest: no recorder installed, call loggertest.New first")
+	}
+	return r
+}
@@ -0,0 +1,85 @@
+package loggertest_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Jsharkc/mygopkg/logger"
+	"github.com/Jsharkc/mygopkg/logger/loggertest"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRecorder(t *testing.T) {
+	before := logger.DefaultLogger
+	t.Run("capture", func(t *testing.T) {
+		rec := loggertest.New(t)
+		assert.Same(t, rec.Logger, logger.DefaultLogger)
+
+		ctx := logger.WithContext(context.Background(), "t-1", "u-1")
+		logger.CtxWarnf(ctx, "quota at %d%%", 90)
+		logger.WithFields(map[string]any{"order": 7}).Info("created order")
+		logger.Debugf("details")
+
+		entries := rec.Entries()
+		assert.Len(t, entries, 3)
+		assert.Equal(t, zapcore.WarnLevel, entries[0].Level)
+		assert.Equal(t, "t-1", entries[0].TraceID)
+		assert.Equal(t, "u-1", entries[0].UserID)
+		assert.Contains(t, entries[0].Caller.File, "loggertest_test.go")
+		assert.Equal(t, []string{"quota at 90%", "created order", "details"}, rec.Messages())
+
+		loggertest.AssertLogged(t, zapcore.WarnLevel, "quota")
+		loggertest.AssertNotLogged(t, zapcore.ErrorLevel, "quota")
+		loggertest.AssertField(t, "created", "order", 7)
+
+		rec.Reset()
+		assert.Equal(t, 0, rec.Len())
+	})
+	assert.Same(t, before, logger.DefaultLogger)
+	assert.Nil(t, loggertest.Current())
+}
+
+// fakeTB records the failures reported by the assertion helpers
+type fakeTB struct {
+	testing.TB
+	errors  []string
+	fatals  []string
+	helpers int
+}
+
+func (f *fakeTB) Helper() { f.helpers++ }
+
+func (f *fakeTB) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeTB) Fatalf(format string, args ...interface{}) {
+	f.fatals = append(f.fatals, fmt.Sprintf(format, args...))
+}
+
+func TestRecorderFailures(t *testing.T) {
+	rec := loggertest.NewWithLevel(t, zapcore.InfoLevel)
+	logger.Debugf("dropped")
+	logger.Errorf("boom")
+	assert.Equal(t, 1, rec.Len())
+
+	fake := &fakeTB{}
+	assert.False(t, rec.AssertLogged(fake, zapcore.InfoLevel, "boom"))
+	assert.False(t, rec.AssertNotLogged(fake, zapcore.ErrorLevel, "boom"))
+	assert.False(t, rec.AssertField(fake, "boom", "missing", 1))
+	assert.Empty(t, fake.fatals)
+	if assert.Len(t, fake.errors, 3) {
+		assert.Contains(t, fake.errors[0], `no INFO entry containing "boom" was logged`)
+		assert.Contains(t, fake.errors[1], `unexpected ERROR entry containing "boom"`)
+		assert.Contains(t, fake.errors[2], `no entry containing "boom" has missing=1`)
+	}
+	assert.Positive(t, fake.helpers)
+
+	// passing assertions report nothing
+	fake = &fakeTB{}
+	assert.True(t, rec.AssertLogged(fake, zapcore.ErrorLevel, "boom"))
+	assert.True(t, rec.AssertNotLogged(fake, zapcore.InfoLevel, "boom"))
+	assert.Empty(t, fake.errors)
+}
@@ -8,7 +8,11 @@ const (
 	PatternPhone     = `^(0|\+?86)?[1-9]\d{10}$`
 	PatternPhonePart = `(0|\+?86)?[1-9]\d{10}`
 	PatternEmail     = "^[a-zA-Z0-9.!#$%&'*+\\/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$"
-	PatternAccount    = `^[a-zA-Z0-9_]{4,20}$`
+	PatternAccount   = `^[a-zA-Z0-9_]{4,20}$`
+	// 以下为可在文本中查找的非锚定模式
+	PatternEmailPart    = "[a-zA-Z0-9.!#$%&'*+\\/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)+"
+	PatternIDCardPart   = `[1-9]\d{5}(?:18|19|20)\d{2}(?:0[1-9]|1[0-2])(?:0[1-9]|[12]\d|3[01])\d{3}[\dXx]`
+	PatternBankCardPart = `[1-9]\d{12,18}`
 )
 
 var (